@@ -0,0 +1,173 @@
+package main
+
+import "math"
+
+// A min cost flow network, for bounding what the rest of a day's seats can cost
+type flowNetwork struct {
+	edges    []flowEdge
+	adjacent [][]int // Edge indexes leaving each node; an edge's reverse is at its index xor 1
+}
+
+type flowEdge struct {
+	to       int
+	capacity int // Left to use
+	cost     int
+}
+
+func (n *flowNetwork) addNode() int {
+	n.adjacent = append(n.adjacent, nil)
+	return len(n.adjacent) - 1
+}
+
+// Returns the index of the edge, whose capacity drops as flow goes through it
+func (n *flowNetwork) addEdge(from int, to int, capacity int, cost int) int {
+	n.adjacent[from] = append(n.adjacent[from], len(n.edges))
+	n.edges = append(n.edges, flowEdge{to: to, capacity: capacity, cost: cost})
+	n.adjacent[to] = append(n.adjacent[to], len(n.edges))
+	n.edges = append(n.edges, flowEdge{to: from, capacity: 0, cost: -cost})
+	return len(n.edges) - 2
+}
+
+// Sends up to the given flow from source to sink, one unit at a time along the cheapest path left
+func (n *flowNetwork) minCostFlow(source int, sink int, flow int) {
+	var (
+		dist      = make([]int, len(n.adjacent))
+		through   = make([]int, len(n.adjacent)) // Edge each node was last reached by
+		queued    = make([]bool, len(n.adjacent))
+		unreached = math.MaxInt
+	)
+
+	for ; flow > 0; flow-- {
+		for i := range dist {
+			dist[i] = unreached
+		}
+		dist[source] = 0
+
+		// Paths are found with Bellman-Ford rather than Dijkstra, since reverse edges have negative costs
+		queue := []int{source}
+		queued[source] = true
+		for len(queue) > 0 {
+			node := queue[0]
+			queue = queue[1:]
+			queued[node] = false
+
+			for _, e := range n.adjacent[node] {
+				edge := n.edges[e]
+				if edge.capacity == 0 {
+					continue
+				}
+				if dist[node]+edge.cost < dist[edge.to] {
+					dist[edge.to] = dist[node] + edge.cost
+					through[edge.to] = e
+					if !queued[edge.to] {
+						queued[edge.to] = true
+						queue = append(queue, edge.to)
+					}
+				}
+			}
+		}
+
+		if dist[sink] == unreached {
+			return
+		}
+		for node := sink; node != source; node = n.edges[through[node]^1].to {
+			n.edges[through[node]].capacity--
+			n.edges[through[node]^1].capacity++
+		}
+	}
+}
+
+// Fewest unfilled seats, then fewest changed seats, then fewest substitutes, then lowest cost any roster could
+// fill the seats with, given the crew each seat can still take. It comes from the cheapest assignment of crew to the seats that keeps to
+// how many more flights each crew member can take today and never seats anyone twice on the same flight, but
+// ignores the other rules between seats. Also returns the crew that assignment puts in each seat, or EMPTY_SEAT
+func (d *daySearch) assignmentBound(seatIndexes []int, crewBySeat [][]int) (seatBound, []int) {
+	var (
+		network    = &flowNetwork{}
+		source     = network.addNode()
+		sink       = network.addNode()
+		crewNodes  = make(map[int]int)
+		legNodes   = make(map[[2]int]int) // Key: crew index and flight index
+		seatEdges  = make([]map[int]int, len(seatIndexes))
+		costScale  = 1
+		assignment = make([]int, len(seatIndexes))
+	)
+
+	// Substitutes cost more than any roster's cost could add up to, changed seats more than every seat being
+	// filled by a substitute and empty seats more than every seat being changed, so the cheapest assignment
+	// fills, changes and substitutes the fewest seats in that order
+	for i, seatIndex := range seatIndexes {
+		highest := 0
+		for _, crewIndex := range crewBySeat[i] {
+			if d.costs[seatIndex][crewIndex] > highest {
+				highest = d.costs[seatIndex][crewIndex]
+			}
+		}
+		costScale += highest
+	}
+	changeScale := costScale * (len(seatIndexes) + 1)
+	emptyCost := changeScale * (len(seatIndexes) + 1)
+
+	for i, seatIndex := range seatIndexes {
+		st := d.seats[seatIndex]
+		seatNode := network.addNode()
+		network.addEdge(source, seatNode, 1, 0)
+		network.addEdge(seatNode, sink, 1, emptyCost)
+
+		seatEdges[i] = make(map[int]int)
+		for _, crewIndex := range crewBySeat[i] {
+			capacity := d.solver.dutyDay[crewIndex].MaxFlights - len(d.today[crewIndex])
+			if capacity <= 0 {
+				continue
+			}
+
+			crewNode, ok := crewNodes[crewIndex]
+			if !ok {
+				crewNode = network.addNode()
+				crewNodes[crewIndex] = crewNode
+				network.addEdge(crewNode, sink, capacity, 0)
+			}
+
+			// Crew who can fly more than once today still only get one seat on each flight
+			if capacity > 1 {
+				leg := [2]int{crewIndex, st.flight}
+				legNode, ok := legNodes[leg]
+				if !ok {
+					legNode = network.addNode()
+					legNodes[leg] = legNode
+					network.addEdge(legNode, crewNode, 1, 0)
+				}
+				crewNode = legNode
+			}
+
+			cost := d.costs[seatIndex][crewIndex]
+			if d.isSubstitute(seatIndex, crewIndex) {
+				cost += costScale
+			}
+			if d.isChange(seatIndex, crewIndex) {
+				cost += changeScale
+			}
+			seatEdges[i][crewIndex] = network.addEdge(seatNode, crewNode, 1, cost)
+		}
+	}
+
+	network.minCostFlow(source, sink, len(seatIndexes))
+
+	var bound seatBound
+	for i, seatIndex := range seatIndexes {
+		assignment[i] = EMPTY_SEAT
+		for crewIndex, e := range seatEdges[i] {
+			if network.edges[e].capacity == 0 {
+				assignment[i] = crewIndex
+			}
+		}
+
+		if assignment[i] == EMPTY_SEAT {
+			bound.unfilled++
+		} else {
+			bound = bound.plus(d.seatScore(seatIndex, assignment[i]))
+		}
+	}
+
+	return bound, assignment
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+/*
+When crew with each Troop to Task code can fly, under "availability" in the flight config, e.g.
+
+	"availability": {
+		"": {},
+		"F": {},
+		"AM": {"until": "1200"},
+		"PM": {"from": "1300"},
+		"SIM": {"types": ["TRAINING"]},
+		"DUTY": {"none": true}
+	}
+
+An empty window is the whole day. "from" and "until" limit the day to flights that start at or after "from" and
+land by "until", and "types" to flights of those types. Crew with a "none" code, like "DUTY" above, can't fly at
+all. Every code in Troop to Task or a JSON schedule input has to be listed, so a code nobody has configured is
+reported rather than guessed at. Codes are matched ignoring case and surrounding spaces.
+*/
+type AvailabilityWindow struct {
+	From  string   `json:"from,omitempty"`  //24 hour clock, e.g. 1300
+	Until string   `json:"until,omitempty"` //24 hour clock, e.g. 1200
+	Types []string `json:"types,omitempty"`
+	None  bool     `json:"none,omitempty"`
+
+	from  time.Time
+	until time.Time
+}
+
+// Codes Troop to Task has always treated as free to fly
+func defaultAvailabilityCodes() map[string]*AvailabilityWindow {
+	return map[string]*AvailabilityWindow{
+		"":    {},
+		"F":   {},
+		"AMR": {},
+	}
+}
+
+func availabilityCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func validateAvailabilityCodes(codes map[string]*AvailabilityWindow) error {
+	for code, window := range codes {
+		if window == nil {
+			return fmt.Errorf("availability code %q is empty; use {} for the whole day", code)
+		}
+		if code != availabilityCode(code) {
+			if _, ok := codes[availabilityCode(code)]; ok {
+				return fmt.Errorf("availability code %q is listed twice", availabilityCode(code))
+			}
+			delete(codes, code)
+			codes[availabilityCode(code)] = window
+		}
+
+		if window.None && (window.From != "" || window.Until != "" || len(window.Types) > 0) {
+			return fmt.Errorf("availability code %q is none, so it can't have from, until or types", code)
+		}
+
+		if window.From != "" {
+			from, err := time.Parse(FLIGHT_TIME_FORMAT, window.From)
+			if err != nil {
+				return fmt.Errorf("availability code %q has from %q; expected a time like 1300", code, window.From)
+			}
+			window.from = from
+		}
+
+		if window.Until != "" {
+			until, err := time.Parse(FLIGHT_TIME_FORMAT, window.Until)
+			if err != nil {
+				return fmt.Errorf("availability code %q has until %q; expected a time like 1200", code, window.Until)
+			}
+			window.until = until
+		}
+
+		if window.From != "" && window.Until != "" && !window.from.Before(window.until) {
+			return fmt.Errorf("availability code %q has from %s, which isn't before until %s", code, window.From, window.Until)
+		}
+
+		for _, flightType := range window.Types {
+			if !isFlightType(flightType) {
+				return fmt.Errorf("availability code %q has unknown flight type %q", code, flightType)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Problem with a code read from the input, or blank if it's listed under "availability"
+func (c *FlightConfig) availabilityCodeProblem(code string) string {
+	if _, ok := c.Availability[availabilityCode(code)]; ok {
+		return ""
+	}
+	return fmt.Sprintf("unknown availability code %q; list it under \"availability\" in the flight config, with {\"none\": true} if crew with it can't fly", code)
+}
+
+// Window for a Troop to Task code, or nil if crew with the code can't fly that day
+func (c *FlightConfig) availabilityWindow(code string) *AvailabilityWindow {
+	window, ok := c.Availability[availabilityCode(code)]
+	if !ok || window.None {
+		return nil
+	}
+	return window
+}
+
+func (w *AvailabilityWindow) allDay() bool {
+	return w.From == "" && w.Until == "" && len(w.Types) == 0
+}
+
+func (w *AvailabilityWindow) allows(flight *Flight) bool {
+	if len(w.Types) > 0 {
+		allowed := false
+		for _, flightType := range w.Types {
+			allowed = allowed || flightType == flight.Type
+		}
+		if !allowed {
+			return false
+		}
+	}
+
+	if w.From == "" && w.Until == "" {
+		return true
+	}
+
+	start, err := time.Parse(FLIGHT_TIME_FORMAT, flight.Time)
+	if err != nil {
+		return false
+	}
+	if w.From != "" && start.Before(w.from) {
+		return false
+	}
+	if w.Until != "" && start.Add(flight.Duration).After(w.until) {
+		return false
+	}
+
+	return true
+}
+
+// e.g. "from 1300, for TRAINING flights"
+func (w *AvailabilityWindow) String() string {
+	parts := []string{}
+	if w.From != "" {
+		parts = append(parts, "from "+w.From)
+	}
+	if w.Until != "" {
+		parts = append(parts, "until "+w.Until)
+	}
+	if len(w.Types) > 0 {
+		parts = append(parts, "for "+strings.Join(w.Types, " and ")+" flights")
+	}
+	if len(parts) == 0 {
+		return "all day"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Whether the crew member's code that day lets them fly the flight
+func (crew *CrewAvailability) canFly(flight *Flight) bool {
+	if !crew.Availabilty[flight.Date] {
+		return false
+	}
+
+	window := flightConfig.availabilityWindow(crew.Codes[flight.Date])
+	return window != nil && window.allows(flight)
+}
+
+// Why the crew member can't fly the flight according to Troop to Task, or blank if they can
+func (crew *CrewAvailability) unavailableReason(flight *Flight) string {
+	available, ok := crew.Availabilty[flight.Date]
+	if !ok {
+		return fmt.Sprintf("not listed for %s in Troop to Task", flight.Date)
+	}
+	if !available {
+		return fmt.Sprintf("unavailable (code %s) in Troop to Task", crew.Codes[flight.Date])
+	}
+	if !crew.canFly(flight) {
+		window := flightConfig.availabilityWindow(crew.Codes[flight.Date])
+		return fmt.Sprintf("only available %s (code %s) in Troop to Task", window, crew.Codes[flight.Date])
+	}
+	return ""
+}
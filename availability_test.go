@@ -0,0 +1,146 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Same day as the 2026-01-05 in the JSON inputs below
+const availabilityDate = "Jan 05 26"
+
+// Swaps in the availability codes for the rest of the test
+func setAvailabilityCodes(t *testing.T, codes map[string]*AvailabilityWindow) {
+	if err := validateAvailabilityCodes(codes); err != nil {
+		t.Fatal(err)
+	}
+
+	config := *flightConfig
+	config.Availability = codes
+	saved := flightConfig
+	flightConfig = &config
+	t.Cleanup(func() { flightConfig = saved })
+}
+
+func TestCanFly(t *testing.T) {
+	setAvailabilityCodes(t, map[string]*AvailabilityWindow{
+		"":     {},
+		"AM":   {Until: "1200"},
+		"PM":   {From: "1300"},
+		"SIM":  {Types: []string{"TRAINING"}},
+		"DUTY": {None: true},
+	})
+
+	tests := []struct {
+		name       string
+		code       string
+		available  bool
+		flightType string
+		flightTime string
+		want       bool
+	}{
+		{name: "all day", code: "", available: true, flightType: "NORMAL", flightTime: "0800", want: true},
+		{name: "unavailable", code: "", available: false, flightType: "NORMAL", flightTime: "0800", want: false},
+		{name: "lands before until", code: "AM", available: true, flightType: "NORMAL", flightTime: "0900", want: true},
+		{name: "lands at until", code: "AM", available: true, flightType: "NORMAL", flightTime: "1000", want: true},
+		{name: "lands after until", code: "AM", available: true, flightType: "NORMAL", flightTime: "1100", want: false},
+		{name: "starts at from", code: "PM", available: true, flightType: "NORMAL", flightTime: "1300", want: true},
+		{name: "starts before from", code: "PM", available: true, flightType: "NORMAL", flightTime: "1200", want: false},
+		{name: "listed type", code: "SIM", available: true, flightType: "TRAINING", flightTime: "0800", want: true},
+		{name: "other type", code: "SIM", available: true, flightType: "NORMAL", flightTime: "0800", want: false},
+		{name: "code matched ignoring case and spaces", code: " am ", available: true, flightType: "NORMAL", flightTime: "0900", want: true},
+		{name: "none even when marked available", code: "DUTY", available: true, flightType: "NORMAL", flightTime: "0800", want: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			crew := &CrewAvailability{
+				Availabilty: map[string]bool{availabilityDate: test.available},
+				Codes:       map[string]string{availabilityDate: test.code},
+			}
+			flight := &Flight{Type: test.flightType, Date: availabilityDate, Time: test.flightTime, Duration: 2 * time.Hour}
+
+			if got := crew.canFly(flight); got != test.want {
+				t.Errorf("canFly = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestScheduleInputAvailabilityCodes(t *testing.T) {
+	setAvailabilityCodes(t, map[string]*AvailabilityWindow{
+		"":     {},
+		"DUTY": {None: true},
+	})
+
+	tests := []struct {
+		name          string
+		availability  string
+		wantAvailable bool
+		wantProblems  []string // Paths of the problems reported
+	}{
+		{
+			name:          "listed code",
+			availability:  `{"available": true}`,
+			wantAvailable: true,
+		},
+		{
+			name:          "none code marked available",
+			availability:  `{"available": true, "code": "DUTY"}`,
+			wantAvailable: false,
+		},
+		{
+			name:         "unknown code",
+			availability: `{"available": true, "code": "LEAVE"}`,
+			wantProblems: []string{`crew[0].availability["2026-01-05"].code`},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fileName := filepath.Join(t.TempDir(), "input.json")
+			document := `{
+				"version": 1,
+				"kind": "schedule-input",
+				"crew": [{"first_name": "Jane", "last_name": "Doe", "rank": "CW2", "status": "PC", "availability": {"2026-01-05": ` + test.availability + `}}],
+				"days": [{"date": "2026-01-05", "flights": [{"type": "NORMAL", "time": "1200"}]}]
+			}`
+			if err := os.WriteFile(fileName, []byte(document), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			schedulePayload, _, _, err := scheduleInputFromJSON(fileName)
+
+			problems := []string{}
+			var validationErr *ValidationError
+			if errors.As(err, &validationErr) {
+				for _, problem := range validationErr.Problems {
+					var jsonErr *JSONError
+					if !errors.As(problem, &jsonErr) {
+						t.Fatalf("problem %v isn't a JSONError", problem)
+					}
+					problems = append(problems, jsonErr.Path)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(test.wantProblems) > 0 {
+				if !reflect.DeepEqual(problems, test.wantProblems) {
+					t.Errorf("problems = %q, want %q", problems, test.wantProblems)
+				}
+				return
+			}
+			if len(problems) > 0 {
+				t.Fatalf("problems = %q, want none", problems)
+			}
+
+			if got := schedulePayload.CrewAvailability[0].Availabilty[availabilityDate]; got != test.wantAvailable {
+				t.Errorf("available = %v, want %v", got, test.wantAvailable)
+			}
+		})
+	}
+}
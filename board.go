@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tealeg/xlsx"
+)
+
+const (
+	LAYOUT_LIST  = "list"  // Flights sheet only: a flight row followed by its crew rows
+	LAYOUT_BOARD = "board" // Board sheet first, one column per day, followed by the list sheets
+
+	BOARD_SHEET       = "Board"
+	BOARD_COL_WIDTH   = 30
+	BOARD_UNFILLED    = "FFFF9999"
+	BOARD_HEADER_FILL = "FFD9D9D9"
+)
+
+var (
+	layouts = []string{LAYOUT_LIST, LAYOUT_BOARD}
+
+	// Fill of each flight type's header on the board
+	flightTypeColors = map[string]string{
+		"TRAINING":    "FFDDEBF7",
+		"MAINTENANCE": "FFFCE4D6",
+		"NORMAL":      "FFE2EFDA",
+	}
+)
+
+func isLayout(layout string) bool {
+	for _, l := range layouts {
+		if layout == l {
+			return true
+		}
+	}
+	return false
+}
+
+func boardStyle(bold bool, fill string) *xlsx.Style {
+	style := xlsx.NewStyle()
+	style.Border = *xlsx.NewBorder("thin", "thin", "thin", "thin")
+	style.ApplyBorder = true
+
+	if bold {
+		style.Font.Bold = true
+		style.ApplyFont = true
+	}
+
+	if fill != "" {
+		style.Fill = *xlsx.NewFill("solid", fill, fill)
+		style.ApplyFill = true
+	}
+
+	return style
+}
+
+// Lays the schedule out like an ops board: a column for each day with a block for each flight, every seat
+// labeled with its status. Unfilled seats are red and flight headers are colored by flight type
+func addBoardSheet(file *xlsx.File, flightSchedules *FlightSchedules) error {
+	sheet, err := file.AddSheet(BOARD_SHEET)
+	if err != nil {
+		return err
+	}
+
+	var (
+		headerStyle   = boardStyle(true, BOARD_HEADER_FILL)
+		seatStyle     = boardStyle(false, "")
+		unfilledStyle = boardStyle(true, BOARD_UNFILLED)
+		gaps          = gapsByFlight(flightSchedules)
+		days          = flightIndexesByDate(flightSchedules)
+	)
+
+	for col, day := range days {
+		date := flightSchedules.Flights[day[0]].Date
+		heading := date
+		if t, err := time.Parse(FULL_DATE_FORMAT, date); err == nil {
+			heading = t.Format("Mon Jan 2")
+		}
+
+		cell := sheet.Cell(0, col)
+		cell.Value = heading
+		cell.SetStyle(headerStyle)
+
+		row := 2
+		for _, flightIndex := range day {
+			flight := flightSchedules.Flights[flightIndex]
+
+			cell := sheet.Cell(row, col)
+			cell.Value = fmt.Sprintf("%s %s", flight.Time, flight.Type)
+			cell.SetStyle(boardStyle(true, flightTypeColors[flight.Type]))
+			row++
+
+			for _, status := range seatStatuses {
+				for _, crew := range flight.crew() {
+					if crew.seatStatus() != status {
+						continue
+					}
+
+					cell := sheet.Cell(row, col)
+					cell.Value = fmt.Sprintf("%s: %s %s %s", crew.seatLabel(), crew.Rank, crew.FirstName, crew.LastName)
+					cell.SetStyle(seatStyle)
+					row++
+				}
+
+				for n := 0; n < gaps[flight][status]; n++ {
+					cell := sheet.Cell(row, col)
+					cell.Value = fmt.Sprintf("%s: unfilled", status)
+					cell.SetStyle(unfilledStyle)
+					row++
+				}
+			}
+
+			row++ // Blank row between flights
+		}
+	}
+
+	if len(days) > 0 {
+		err = sheet.SetColWidth(0, len(days)-1, BOARD_COL_WIDTH)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
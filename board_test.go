@@ -0,0 +1,65 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/tealeg/xlsx"
+)
+
+func TestAddBoardSheet(t *testing.T) {
+	var (
+		maintenance = &Flight{
+			Type: "MAINTENANCE", Date: "Mar 02 26", Time: "0900",
+			PC:  &CrewMember{FirstName: "Jane", LastName: "Doe", Rank: "CW3", Status: "PC"},
+			PIs: []*CrewMember{{FirstName: "Lee", LastName: "Poe", Rank: "CW2", Status: "PC", Seat: "PI"}},
+		}
+		training = &Flight{Type: "TRAINING", Date: "Mar 03 26", Time: "0800"}
+		normal   = &Flight{Type: "NORMAL", Date: "Mar 02 26", Time: "1200", FEs: []*CrewMember{{FirstName: "Kim", LastName: "Roe", Rank: "SGT", Status: "FE"}}}
+	)
+	flightSchedules := &FlightSchedules{
+		Flights:  []*Flight{maintenance, training, normal},
+		Unfilled: []*UnfilledSeat{{FlightIndex: 1, Status: "PC"}, {FlightIndex: 2, Status: "PI"}, {FlightIndex: 2, Status: "PI"}},
+	}
+
+	file := xlsx.NewFile()
+	if err := addBoardSheet(file, flightSchedules); err != nil {
+		t.Fatal(err)
+	}
+	sheet := file.Sheet[BOARD_SHEET]
+
+	column := func(col int) []string {
+		values := []string{}
+		for _, row := range sheet.Rows {
+			if col < len(row.Cells) {
+				values = append(values, row.Cells[col].Value)
+			} else {
+				values = append(values, "")
+			}
+		}
+		for len(values) > 0 && values[len(values)-1] == "" {
+			values = values[:len(values)-1]
+		}
+		return values
+	}
+
+	wantMonday := []string{
+		"Mon Mar 2", "",
+		"0900 MAINTENANCE", "PC: CW3 Jane Doe", "PC as PI: CW2 Lee Poe", "",
+		"1200 NORMAL", "PI: unfilled", "PI: unfilled", "FE: SGT Kim Roe",
+	}
+	if got := column(0); !reflect.DeepEqual(got, wantMonday) {
+		t.Errorf("Monday = %q, want %q", got, wantMonday)
+	}
+	wantTuesday := []string{"Tue Mar 3", "", "0800 TRAINING", "PC: unfilled"}
+	if got := column(1); !reflect.DeepEqual(got, wantTuesday) {
+		t.Errorf("Tuesday = %q, want %q", got, wantTuesday)
+	}
+
+	if fill := sheet.Cell(7, 0).GetStyle().Fill.FgColor; fill != BOARD_UNFILLED {
+		t.Errorf("unfilled seat fill = %q, want %q", fill, BOARD_UNFILLED)
+	}
+	if fill := sheet.Cell(2, 0).GetStyle().Fill.FgColor; fill != flightTypeColors["MAINTENANCE"] {
+		t.Errorf("maintenance header fill = %q, want %q", fill, flightTypeColors["MAINTENANCE"])
+	}
+}
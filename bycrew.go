@@ -0,0 +1,178 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tealeg/xlsx"
+)
+
+const BY_CREW_SHEET = "By Crew"
+
+var byCrewSheetHeading = []string{
+	"Status",
+	"Rank",
+	"First Name",
+	"Last Name",
+	"Date",
+	"Time",
+	"Flight Type",
+	"Fellow Crew",
+	"Availability",
+}
+
+// One crew member's flights, for the by crew sheet
+type crewDuties struct {
+	crew         *CrewMember
+	availability *CrewAvailability // nil when the crew member isn't in Troop to Task, e.g. on a day kept by a re-plan
+	flights      []*Flight
+}
+
+// Everyone in Troop to Task in its order, followed by anyone else who's seated, each with the flights they're on
+func dutiesByCrew(flightSchedules *FlightSchedules) []*crewDuties {
+	var (
+		duties = []*crewDuties{}
+		byName = make(map[string]*crewDuties)
+	)
+
+	crew := append([]*CrewAvailability{}, flightSchedules.Crew...)
+	sort.SliceStable(crew, func(a, b int) bool {
+		return crew[a].Row < crew[b].Row
+	})
+
+	for _, c := range crew {
+		d := &crewDuties{
+			crew:         &CrewMember{FirstName: c.FirstName, LastName: c.LastName, Rank: c.Rank, Status: c.Status},
+			availability: c,
+			flights:      []*Flight{},
+		}
+		byName[hoursKey(c.FirstName, c.LastName)] = d
+		duties = append(duties, d)
+	}
+
+	for _, flight := range flightSchedules.Flights {
+		for _, c := range flight.crew() {
+			key := hoursKey(c.FirstName, c.LastName)
+			d, ok := byName[key]
+			if !ok {
+				d = &crewDuties{crew: c, flights: []*Flight{}}
+				byName[key] = d
+				duties = append(duties, d)
+			}
+			d.flights = append(d.flights, flight)
+		}
+	}
+
+	return duties
+}
+
+// Who else is on the flight, e.g. "PC CPT Jane Doe; FE SGT John Roe"
+func fellowCrew(flight *Flight, crew *CrewMember) string {
+	others := []string{}
+	for _, c := range flight.crew() {
+		if hoursKey(c.FirstName, c.LastName) == hoursKey(crew.FirstName, crew.LastName) {
+			continue
+		}
+		others = append(others, fmt.Sprintf("%s %s %s %s", c.seatLabel(), c.Rank, c.FirstName, c.LastName))
+	}
+	return strings.Join(others, "; ")
+}
+
+// How many planning days the crew member is available, and the Troop to Task code of each day they aren't or
+// are only in part, e.g. "available 5 of 7 days; AM (until 1200) on Jan 05 26; L on Jan 06 26, Jan 07 26"
+func availabilitySummary(availability *CrewAvailability) (string, error) {
+	if availability == nil {
+		return "not in Troop to Task", nil
+	}
+
+	var (
+		available  = 0
+		codes      = []string{}
+		daysByCode = make(map[string][]string) // Key: availability code, with its window if any; Value: dates
+		notListed  = []string{}
+	)
+
+	for _, date := range dates {
+		inputDate, err := time.Parse(INPUT_DATE_FORMAT, date)
+		if err != nil {
+			return "", err
+		}
+		date := inputDate.Format(FULL_DATE_FORMAT)
+
+		ok, listed := availability.Availabilty[date]
+		if !listed {
+			notListed = append(notListed, date)
+			continue
+		}
+
+		window := flightConfig.availabilityWindow(availability.Codes[date])
+		if ok {
+			available++
+			if window == nil || window.allDay() {
+				continue
+			}
+		}
+
+		// Days off, and days that are available only in part
+		label := availability.Codes[date]
+		if ok {
+			label = fmt.Sprintf("%s (%s)", label, window)
+		} else if label == "" {
+			label = "unavailable"
+		}
+
+		if _, seen := daysByCode[label]; !seen {
+			codes = append(codes, label)
+		}
+		daysByCode[label] = append(daysByCode[label], date)
+	}
+
+	summary := []string{fmt.Sprintf("available %d of %d days", available, len(dates))}
+	for _, label := range codes {
+		summary = append(summary, fmt.Sprintf("%s on %s", label, strings.Join(daysByCode[label], ", ")))
+	}
+	if len(notListed) > 0 {
+		summary = append(summary, fmt.Sprintf("not listed on %s", strings.Join(notListed, ", ")))
+	}
+
+	return strings.Join(summary, "; "), nil
+}
+
+// Each crew member's flights with who they fly with, so nobody has to scan the whole schedule for their own
+func addByCrewSheet(file *xlsx.File, flightSchedules *FlightSchedules) error {
+	sheet, err := file.AddSheet(BY_CREW_SHEET)
+	if err != nil {
+		return err
+	}
+
+	addSheetHeading(sheet, byCrewSheetHeading)
+	for _, d := range dutiesByCrew(flightSchedules) {
+		name := []string{d.crew.Status, d.crew.Rank, d.crew.FirstName, d.crew.LastName}
+
+		if len(d.flights) == 0 {
+			summary, err := availabilitySummary(d.availability)
+			if err != nil {
+				return err
+			}
+
+			row := sheet.AddRow()
+			for _, value := range append(name, "-", "-", "-", "-", summary) {
+				cell := row.AddCell()
+				cell.Value = value
+			}
+			continue
+		}
+
+		for _, flight := range d.flights {
+			row := sheet.AddRow()
+			for _, value := range append(name, flight.Date, flight.Time, flight.Type, fellowCrew(flight, d.crew)) {
+				cell := row.AddCell()
+				cell.Value = value
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDutiesByCrew(t *testing.T) {
+	var (
+		jane = &CrewAvailability{FirstName: "Jane", LastName: "Doe", Rank: "CW3", Status: "PC", Row: 4}
+		john = &CrewAvailability{FirstName: "John", LastName: "Roe", Rank: "CW2", Status: "PI", Row: 2}
+		kept = &CrewMember{FirstName: "Kim", LastName: "Poe", Rank: "SGT", Status: "FE"} // Seated on a day a re-plan kept
+	)
+	morning := &Flight{
+		Type: "MAINTENANCE", Date: "Mar 02 26", Time: "0900",
+		PC:  &CrewMember{FirstName: "jane ", LastName: "DOE", Rank: "CW3", Status: "PC"}, // Only the hours key matches Troop to Task
+		FEs: []*CrewMember{kept},
+	}
+	afternoon := &Flight{Type: "NORMAL", Date: "Mar 02 26", Time: "1300", PC: &CrewMember{FirstName: "Jane", LastName: "Doe", Rank: "CW3", Status: "PC"}}
+	flightSchedules := &FlightSchedules{Crew: []*CrewAvailability{jane, john}, Flights: []*Flight{morning, afternoon}}
+
+	got := []string{}
+	for _, d := range dutiesByCrew(flightSchedules) {
+		line := d.crew.LastName
+		for _, flight := range d.flights {
+			line += " " + flight.Time
+		}
+		got = append(got, line)
+	}
+	if want := []string{"Roe", "Doe 0900 1300", "Poe 0900"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("duties = %q, want %q", got, want)
+	}
+
+	if got, want := fellowCrew(morning, kept), "PC CW3 jane  DOE"; got != want {
+		t.Errorf("fellowCrew = %q, want %q", got, want)
+	}
+	if got := fellowCrew(afternoon, afternoon.PC); got != "" {
+		t.Errorf("fellowCrew of the only crew = %q, want none", got)
+	}
+}
+
+func TestAvailabilitySummary(t *testing.T) {
+	savedDates, savedFlights, savedConfig := dates, numFlightsByDate, flightConfig
+	defer func() { dates, numFlightsByDate, flightConfig = savedDates, savedFlights, savedConfig }()
+
+	config := *flightConfig
+	config.Availability = defaultAvailabilityCodes()
+	config.Availability["AM"] = &AvailabilityWindow{Until: "1200"}
+	config.Availability["L"] = &AvailabilityWindow{None: true}
+	if err := validateAvailabilityCodes(config.Availability); err != nil {
+		t.Fatal(err)
+	}
+	flightConfig = &config
+
+	dates, numFlightsByDate = []string{}, make(map[string]int)
+	for day := 2; day <= 6; day++ {
+		addPlanningDate(time.Date(2026, time.March, day, 0, 0, 0, 0, time.UTC), 0)
+	}
+
+	crew := &CrewAvailability{
+		Availabilty: map[string]bool{"Mar 02 26": true, "Mar 03 26": true, "Mar 04 26": false, "Mar 05 26": false},
+		Codes:       map[string]string{"Mar 02 26": "F", "Mar 03 26": "AM", "Mar 04 26": "L", "Mar 05 26": "L"},
+	}
+
+	got, err := availabilitySummary(crew)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "available 2 of 5 days; AM (until 1200) on Mar 03 26; L on Mar 04 26, Mar 05 26; not listed on Mar 06 26"
+	if got != want {
+		t.Errorf("availabilitySummary = %q, want %q", got, want)
+	}
+
+	if got, _ := availabilitySummary(nil); got != "not in Troop to Task" {
+		t.Errorf("availabilitySummary(nil) = %q, want %q", got, "not in Troop to Task")
+	}
+}
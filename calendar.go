@@ -0,0 +1,214 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	CALENDAR_PRODUCT_ID  = "-//fly-scheduler//Flight Schedules//EN"
+	CALENDAR_UID_DOMAIN  = "fly-scheduler"
+	CALENDAR_TIME_FORMAT = "20060102T150405" // Floating local time, the same wall clock as Troop to Task
+	CALENDAR_LINE_OCTETS = 75
+)
+
+// Writes one event per flight to fileName, and a calendar of their own flights for each crew member to a
+// folder next to it, e.g. files/FlightSchedules.ics and files/FlightSchedules-crew/Doe_Jane.ics
+func exportICSResult(flightSchedules *FlightSchedules, fileName string) error {
+	var (
+		stamp = time.Now().UTC()
+		uids  = flightUIDs(flightSchedules)
+	)
+
+	events := []string{}
+	for _, flight := range flightSchedules.Flights {
+		event, err := flightEvent(flightSchedules, flight, uids[flight], nil, stamp)
+		if err != nil {
+			return err
+		}
+		events = append(events, event)
+	}
+
+	err := writeCalendar(fileName, "Flight Schedules", events)
+	if err != nil {
+		return err
+	}
+
+	crewDir := strings.TrimSuffix(fileName, filepath.Ext(fileName)) + "-crew"
+	for _, d := range dutiesByCrew(flightSchedules) {
+		if len(d.flights) == 0 {
+			continue
+		}
+
+		events := []string{}
+		for _, flight := range d.flights {
+			event, err := flightEvent(flightSchedules, flight, uids[flight], d.crew, stamp)
+			if err != nil {
+				return err
+			}
+			events = append(events, event)
+		}
+
+		name := fmt.Sprintf("%s_%s.ics", calendarFileName(d.crew.LastName), calendarFileName(d.crew.FirstName))
+		err := writeCalendar(filepath.Join(crewDir, name), fmt.Sprintf("Flights for %s %s %s", d.crew.Rank, d.crew.FirstName, d.crew.LastName), events)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// UIDs built from the date, time and type, so exporting the schedule again updates each flight's event instead
+// of adding another. Identical flights on the same day are numbered in order
+func flightUIDs(flightSchedules *FlightSchedules) map[*Flight]string {
+	var (
+		uids = make(map[*Flight]string)
+		seen = make(map[string]int) // Key: flight key; Value: flights with that key so far
+	)
+
+	for _, flight := range flightSchedules.Flights {
+		key := flightKey(flight)
+		seen[key]++
+		uids[flight] = fmt.Sprintf("%s-%s-%s-%d@%s", calendarDate(flight.Date), flight.Time, strings.ToLower(flight.Type), seen[key], CALENDAR_UID_DOMAIN)
+	}
+
+	return uids
+}
+
+// VEVENT for a flight. For a crew member's own calendar, the summary says which seat they're in
+func flightEvent(flightSchedules *FlightSchedules, flight *Flight, uid string, crew *CrewMember, stamp time.Time) (string, error) {
+	start, err := flightStart(flight)
+	if err != nil {
+		return "", err
+	}
+
+	summary := fmt.Sprintf("%s flight", flight.Type)
+	if crew != nil {
+		summary = fmt.Sprintf("%s flight (%s)", flight.Type, crew.seatLabel())
+	}
+
+	description := []string{}
+	for _, c := range flight.crew() {
+		description = append(description, fmt.Sprintf("%s %s %s %s", c.seatLabel(), c.Rank, c.FirstName, c.LastName))
+	}
+	gaps := flightGaps(flightSchedules, flight)
+	for _, status := range seatStatuses {
+		if open := gaps[status]; open > 0 {
+			description = append(description, fmt.Sprintf("Unfilled: %d %s", open, status))
+		}
+	}
+
+	lines := []string{
+		"BEGIN:VEVENT",
+		"UID:" + uid,
+		"DTSTAMP:" + stamp.Format(CALENDAR_TIME_FORMAT) + "Z",
+		"SEQUENCE:" + fmt.Sprint(stamp.Unix()), // Later exports are newer versions of the same event
+		"DTSTART:" + start.Format(CALENDAR_TIME_FORMAT),
+		"DTEND:" + start.Add(flight.Duration).Format(CALENDAR_TIME_FORMAT),
+		"SUMMARY:" + calendarText(summary),
+		"DESCRIPTION:" + calendarText(strings.Join(description, "\n")),
+	}
+	for _, c := range flight.crew() {
+		lines = append(lines, fmt.Sprintf("ATTENDEE;CN=%s;ROLE=REQ-PARTICIPANT:urn:%s:crew:%s",
+			calendarParam(fmt.Sprintf("%s %s %s", c.Rank, c.FirstName, c.LastName)), CALENDAR_UID_DOMAIN, calendarFileName(hoursKey(c.FirstName, c.LastName))))
+	}
+	lines = append(lines, "END:VEVENT")
+
+	return strings.Join(lines, "\n"), nil
+}
+
+// Unfilled seats of the flight by status
+func flightGaps(flightSchedules *FlightSchedules, flight *Flight) map[string]int {
+	gaps := make(map[string]int)
+	for _, seat := range flightSchedules.Unfilled {
+		if flightSchedules.Flights[seat.FlightIndex] == flight {
+			gaps[seat.Status]++
+		}
+	}
+	return gaps
+}
+
+func writeCalendar(fileName string, name string, events []string) error {
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:" + CALENDAR_PRODUCT_ID,
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"X-WR-CALNAME:" + calendarText(name),
+	}
+	lines = append(lines, events...)
+	lines = append(lines, "END:VCALENDAR")
+
+	var b strings.Builder
+	for _, line := range strings.Split(strings.Join(lines, "\n"), "\n") {
+		b.WriteString(foldCalendarLine(line))
+		b.WriteString("\r\n")
+	}
+
+	if dir := filepath.Dir(fileName); dir != "." {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return err
+		}
+	}
+
+	return os.WriteFile(fileName, []byte(b.String()), 0600)
+}
+
+// Splits lines longer than 75 octets, continuing them on lines that start with a space, without
+// breaking a UTF-8 character
+func foldCalendarLine(line string) string {
+	var (
+		b     strings.Builder
+		width = 0
+	)
+
+	for _, r := range line {
+		size := len(string(r))
+		if width+size > CALENDAR_LINE_OCTETS {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += size
+	}
+
+	return b.String()
+}
+
+// Escapes TEXT values; newlines are escaped so every content line stays on one line until folded
+func calendarText(val string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(val)
+}
+
+// Quotes parameter values, which can't hold double quotes at all
+func calendarParam(val string) string {
+	return `"` + strings.ReplaceAll(val, `"`, "'") + `"`
+}
+
+// Jan 05 26 -> 20260105
+func calendarDate(date string) string {
+	t, err := time.Parse(FULL_DATE_FORMAT, date)
+	if err != nil {
+		return date
+	}
+	return t.Format("20060102")
+}
+
+// Keeps letters and digits, so names are safe in file names and URIs
+func calendarFileName(val string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		if r == ' ' || r == '-' || r == '_' {
+			return '-'
+		}
+		return -1
+	}, strings.TrimSpace(val))
+}
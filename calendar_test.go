@@ -0,0 +1,100 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+const calendarTestDate = "Jan 05 26"
+
+// Flights on calendarTestDate, each written as its time and type followed by the first names of its PIs, e.g. "0800
+// NORMAL PI0 PI1"
+func calendarSchedule(flights ...string) *FlightSchedules {
+	flightSchedules := &FlightSchedules{Flights: []*Flight{}, Unfilled: []*UnfilledSeat{}}
+	for _, description := range flights {
+		fields := strings.Fields(description)
+		flight := &Flight{Time: fields[0], Type: fields[1], Date: calendarTestDate, Duration: 2 * time.Hour}
+		for _, firstName := range fields[2:] {
+			flight.PIs = append(flight.PIs, &CrewMember{FirstName: firstName, LastName: "Doe", Rank: "CW2", Status: "PI"})
+		}
+		flightSchedules.Flights = append(flightSchedules.Flights, flight)
+	}
+	return flightSchedules
+}
+
+func TestFlightUIDs(t *testing.T) {
+	tests := []struct {
+		name    string
+		flights []string // As for calendarSchedule
+		want    []string
+	}{
+		{
+			name:    "one flight",
+			flights: []string{"0800 NORMAL PI0"},
+			want:    []string{"20260105-0800-normal-1@fly-scheduler"},
+		},
+		{
+			name:    "crew don't change the UID",
+			flights: []string{"0800 NORMAL PI1 PI2"},
+			want:    []string{"20260105-0800-normal-1@fly-scheduler"},
+		},
+		{
+			name:    "flights of different types at the same time",
+			flights: []string{"0800 NORMAL", "0800 TRAINING"},
+			want:    []string{"20260105-0800-normal-1@fly-scheduler", "20260105-0800-training-1@fly-scheduler"},
+		},
+		{
+			name:    "identical flights numbered in order",
+			flights: []string{"1200 NORMAL PI0", "1700 NORMAL", "1200 NORMAL PI1"},
+			want:    []string{"20260105-1200-normal-1@fly-scheduler", "20260105-1700-normal-1@fly-scheduler", "20260105-1200-normal-2@fly-scheduler"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			flightSchedules := calendarSchedule(test.flights...)
+			uids := flightUIDs(flightSchedules)
+
+			got := []string{}
+			for _, flight := range flightSchedules.Flights {
+				got = append(got, uids[flight])
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("UIDs = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+// Exporting again after the crew change has to update the same events rather than add new ones
+func TestExportICSResultKeepsUIDs(t *testing.T) {
+	uidPattern := regexp.MustCompile(`(?m)^UID:(.*)\r$`)
+
+	exportUIDs := func(flightSchedules *FlightSchedules) []string {
+		fileName := filepath.Join(t.TempDir(), "FlightSchedules.ics")
+		if err := exportICSResult(flightSchedules, fileName); err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(fileName)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		uids := []string{}
+		for _, match := range uidPattern.FindAllStringSubmatch(string(data), -1) {
+			uids = append(uids, match[1])
+		}
+		return uids
+	}
+
+	before := exportUIDs(calendarSchedule("0800 NORMAL PI0", "1200 NORMAL PI1"))
+	after := exportUIDs(calendarSchedule("0800 NORMAL PI1", "1200 NORMAL PI0 PI2"))
+	if len(before) != 2 || !reflect.DeepEqual(before, after) {
+		t.Errorf("UIDs = %q after the crew changed, want %q", after, before)
+	}
+}
@@ -0,0 +1,435 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	EXIT_OK       = 0
+	EXIT_FAILURE  = 1 // Input couldn't be read, or the schedule couldn't be written
+	EXIT_USAGE    = 2
+	EXIT_UNFILLED = 3 // Schedule was written, but some seats couldn't be filled
+	EXIT_INPUT    = 4 // Input workbooks are missing or have problems that need fixing
+
+	DEFAULT_FLIGHTS_PER_DAY = 3
+	DEFAULT_PLANNING_DAYS   = 7
+	MAX_PLANNING_DAYS       = 62
+)
+
+const usage = `Usage:
+  fly-scheduler                   open the scheduler window
+  fly-scheduler generate [flags]  generate flight schedules without a window
+  fly-scheduler replan [flags]    re-crew a written schedule after availability changes, keeping
+                                  everyone who can still fly in their seat
+  fly-scheduler diff [flags] OLD NEW
+                                  compare two written schedules
+  fly-scheduler schema KIND       print the JSON Schema for schedule-input or flight-schedules documents
+
+Files ending in .json are read and written as JSON instead of workbooks: --input takes a
+schedule-input document, which brings its own days and flights, and --out, --previous and
+diff take flight-schedules documents. --write-input saves what a schedule was planned from
+as a schedule-input document, to plan it again later.
+
+Exit codes: 0 success, 1 failure, 2 bad usage, 3 schedule written with unfilled seats,
+4 input workbooks missing or invalid
+`
+
+// Repeatable --pin flag
+type pinFlags struct {
+	pins []*Pin
+}
+
+func (p *pinFlags) String() string {
+	values := []string{}
+	for _, pin := range p.pins {
+		values = append(values, pin.String())
+	}
+	return strings.Join(values, "; ")
+}
+
+func (p *pinFlags) Set(value string) error {
+	pin, err := parsePin(value)
+	if err != nil {
+		return err
+	}
+
+	p.pins = append(p.pins, pin)
+	return nil
+}
+
+// Headless entry point; returns the process exit code
+func runCommand(args []string) int {
+	switch args[0] {
+	case "generate":
+		return runGenerate(args[1:], os.Stdout, os.Stderr)
+	case "replan":
+		return runReplan(args[1:], os.Stdout, os.Stderr)
+	case "diff":
+		return runDiff(args[1:], os.Stdout, os.Stderr)
+	case "schema":
+		return runSchema(args[1:], os.Stdout, os.Stderr)
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(os.Stdout, usage)
+		return EXIT_OK
+	}
+
+	fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", args[0], usage)
+	return EXIT_USAGE
+}
+
+func runGenerate(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("generate", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+
+	start := flags.String("start", "", "first day to schedule (M/D/YYYY); defaults to today")
+	flightCounts := flags.String("flights", "", fmt.Sprintf("comma separated number of normal flights for each day; defaults to %d days using default_normal from the config", DEFAULT_PLANNING_DAYS))
+	sf := newScheduleFlags(flags)
+
+	if code, ok := sf.parse(args, stderr); !ok {
+		return code
+	}
+
+	if isJSONFile(*sf.input) {
+		if *start != "" || *flightCounts != "" {
+			fmt.Fprintf(stderr, "--start and --flights can't be used with %s, which lists its own days and flights\n", *sf.input)
+			return EXIT_USAGE
+		}
+		if code, ok := sf.loadConfig(stderr); !ok {
+			return code
+		}
+
+		schedulePayload, flightSchedules, err := generateSchedule(sf.options())
+		return reportSchedule(schedulePayload, flightSchedules, err, *sf.out, stdout, stderr)
+	}
+
+	startDate := time.Now()
+	if *start != "" {
+		var err error
+		startDate, err = time.Parse(INPUT_DATE_FORMAT, *start)
+		if err != nil {
+			fmt.Fprintf(stderr, "invalid --start %q: expected a date like 1/2/2026\n", *start)
+			return EXIT_USAGE
+		}
+	}
+
+	if code, ok := sf.loadConfig(stderr); !ok {
+		return code
+	}
+
+	flightsPerDay, err := parseFlightCounts(*flightCounts, startDate)
+	if err != nil {
+		fmt.Fprintf(stderr, "invalid --flights %q: %s\n", *flightCounts, err)
+		return EXIT_USAGE
+	}
+
+	for i, flights := range flightsPerDay {
+		addPlanningDate(startDate.AddDate(0, 0, i), flights)
+	}
+
+	schedulePayload, flightSchedules, err := generateSchedule(sf.options())
+	return reportSchedule(schedulePayload, flightSchedules, err, *sf.out, stdout, stderr)
+}
+
+func runReplan(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("replan", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+
+	previous := flags.String("previous", OUTPUT_FILE, "flight schedules workbook or JSON document to re-plan")
+	today := flags.String("today", "", "first day that can still change (M/D/YYYY); earlier days are kept as they are. Defaults to today")
+	sf := newScheduleFlags(flags)
+
+	if code, ok := sf.parse(args, stderr); !ok {
+		return code
+	}
+
+	todayDate := time.Now()
+	if *today != "" {
+		var err error
+		todayDate, err = time.Parse(INPUT_DATE_FORMAT, *today)
+		if err != nil {
+			fmt.Fprintf(stderr, "invalid --today %q: expected a date like 1/2/2026\n", *today)
+			return EXIT_USAGE
+		}
+	}
+
+	if code, ok := sf.loadConfig(stderr); !ok {
+		return code
+	}
+
+	options := sf.options()
+
+	schedulePayload, flightSchedules, err := replanSchedule(options, *previous, todayDate)
+	if err == nil {
+		err = exportSchedule(schedulePayload, flightSchedules, options)
+	}
+
+	code := reportSchedule(schedulePayload, flightSchedules, err, *sf.out, stdout, stderr)
+	if err == nil {
+		fmt.Fprintf(stdout, "%d changes from %s\n", len(flightSchedules.Changes), *previous)
+		for _, change := range flightSchedules.Changes {
+			fmt.Fprintln(stdout, "   ", change)
+		}
+	}
+
+	return code
+}
+
+func runDiff(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("diff", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+
+	configFile := flags.String("config", FLIGHT_CONFIG_FILE, "flight templates the schedules were made with (JSON or YAML)")
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return EXIT_OK
+		}
+		return EXIT_USAGE
+	}
+	if flags.NArg() != 2 {
+		fmt.Fprintf(stderr, "expected two written schedules to compare\n\n%s", usage)
+		return EXIT_USAGE
+	}
+
+	config, err := loadFlightConfig(*configFile)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return EXIT_FAILURE
+	}
+	flightConfig = config
+
+	diff, err := diffScheduleFiles(flags.Arg(0), flags.Arg(1))
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		if isInputError(err) {
+			return EXIT_INPUT
+		}
+		return EXIT_FAILURE
+	}
+
+	if diff.empty() {
+		fmt.Fprintln(stdout, "No differences")
+		return EXIT_OK
+	}
+
+	printSection := func(heading string, lines []string) {
+		if len(lines) == 0 {
+			return
+		}
+		fmt.Fprintln(stdout, heading)
+		for _, line := range lines {
+			fmt.Fprintln(stdout, "   ", line)
+		}
+	}
+
+	lines := []string{}
+	for _, flight := range diff.AddedFlights {
+		lines = append(lines, flightKey(flight))
+	}
+	printSection("Flights added:", lines)
+
+	lines = []string{}
+	for _, flight := range diff.RemovedFlights {
+		lines = append(lines, flightKey(flight))
+	}
+	printSection("Flights removed:", lines)
+
+	lines = []string{}
+	for _, change := range diff.Changes {
+		lines = append(lines, change.String())
+	}
+	printSection("Crew:", lines)
+
+	lines = []string{}
+	for _, gap := range diff.Gaps {
+		lines = append(lines, gap.String())
+	}
+	printSection("Gaps:", lines)
+
+	lines = []string{}
+	for _, load := range diff.Loads {
+		lines = append(lines, load.String())
+	}
+	printSection("Load:", lines)
+
+	return EXIT_OK
+}
+
+func runSchema(args []string, stdout io.Writer, stderr io.Writer) int {
+	if len(args) != 1 {
+		fmt.Fprintf(stderr, "expected %s or %s\n\n%s", SCHEDULE_INPUT_KIND, FLIGHT_SCHEDULES_KIND, usage)
+		return EXIT_USAGE
+	}
+
+	schema, err := jsonSchema(args[0])
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return EXIT_USAGE
+	}
+
+	stdout.Write(schema)
+	return EXIT_OK
+}
+
+// Flags shared by the commands that write flight schedules
+type scheduleFlags struct {
+	flags        *flag.FlagSet
+	input        *string
+	crew         *string
+	out          *string
+	layout       *string
+	calendar     *string
+	configFile   *string
+	historyFile  *string
+	historyWeeks *int
+	pairing      *string
+	pins         *pinFlags
+	inputCopy    *string
+}
+
+func newScheduleFlags(flags *flag.FlagSet) *scheduleFlags {
+	sf := &scheduleFlags{
+		flags:        flags,
+		input:        flags.String("input", SCHEDULE_FILE, "Troop to Task workbook, or a schedule-input JSON document"),
+		crew:         flags.String("crew", CREW_FILE, "crew hours workbook (skipped if the default doesn't exist)"),
+		out:          flags.String("out", OUTPUT_FILE, "workbook, or .json file, to write the flight schedules to"),
+		layout:       flags.String("layout", LAYOUT_LIST, "workbook layout: list, or board for a column per day ahead of the list"),
+		calendar:     flags.String("ics", "", "also write the flights to this iCalendar file, with a calendar per crew member in a folder next to it"),
+		configFile:   flags.String("config", FLIGHT_CONFIG_FILE, "flight templates and daily flight mix (JSON or YAML)"),
+		historyFile:  flags.String("history", HISTORY_FILE, "assignments from previous runs, used and updated for fairness; empty to skip"),
+		historyWeeks: flags.Int("history-weeks", DEFAULT_HISTORY_WEEKS, "weeks of history to balance flights over"),
+		pairing:      flags.String("pairing", PAIRING_FILE, "must-pair, prefer-pair, never-pair and progression rules (JSON; skipped if the default doesn't exist)"),
+		pins:         &pinFlags{},
+		inputCopy:    flags.String("write-input", "", "also write the crew, days, flights and pins planned from to this schedule-input JSON document"),
+	}
+	flags.Var(sf.pins, "pin", "put crew on a flight, e.g. \"1/6/2026 0900 MAINTENANCE=Doe, Jane\"; the flight type is optional and the flag can be repeated")
+
+	return sf
+}
+
+// Parses the command line; when it returns false, the command should exit with the returned code
+func (sf *scheduleFlags) parse(args []string, stderr io.Writer) (int, bool) {
+	if err := sf.flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return EXIT_OK, false
+		}
+		return EXIT_USAGE, false
+	}
+	if sf.flags.NArg() > 0 {
+		fmt.Fprintf(stderr, "unexpected arguments: %s\n", strings.Join(sf.flags.Args(), " "))
+		return EXIT_USAGE, false
+	}
+	if !isLayout(*sf.layout) {
+		fmt.Fprintf(stderr, "invalid --layout %q: expected %s or %s\n", *sf.layout, LAYOUT_LIST, LAYOUT_BOARD)
+		return EXIT_USAGE, false
+	}
+	if *sf.historyWeeks < 0 {
+		fmt.Fprintf(stderr, "invalid --history-weeks %d: can't be negative\n", *sf.historyWeeks)
+		return EXIT_USAGE, false
+	}
+
+	return EXIT_OK, true
+}
+
+func (sf *scheduleFlags) loadConfig(stderr io.Writer) (int, bool) {
+	config, err := loadFlightConfig(*sf.configFile)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return EXIT_FAILURE, false
+	}
+	flightConfig = config
+
+	return EXIT_OK, true
+}
+
+func (sf *scheduleFlags) options() *GenerateOptions {
+	options := NewGenerateOptions()
+	options.ScheduleFile = *sf.input
+	options.CrewFile = *sf.crew
+	options.OutputFile = *sf.out
+	options.Layout = *sf.layout
+	options.CalendarFile = *sf.calendar
+	options.HistoryFile = *sf.historyFile
+	options.HistoryWeeks = *sf.historyWeeks
+	options.PairingFile = *sf.pairing
+	options.Pins = sf.pins.pins
+	options.InputCopy = *sf.inputCopy
+
+	if _, err := os.Stat(options.CrewFile); os.IsNotExist(err) && options.CrewFile == CREW_FILE {
+		options.CrewFile = ""
+	}
+	if _, err := os.Stat(options.PairingFile); os.IsNotExist(err) && options.PairingFile == PAIRING_FILE {
+		options.PairingFile = ""
+	}
+
+	// A JSON input carries its own hours, so only join the crew workbook when it's asked for
+	crewSet := false
+	sf.flags.Visit(func(f *flag.Flag) {
+		crewSet = crewSet || f.Name == "crew"
+	})
+	if isJSONFile(options.ScheduleFile) && !crewSet {
+		options.CrewFile = ""
+	}
+
+	return options
+}
+
+// Prints the outcome of writing flight schedules and returns the exit code for it
+func reportSchedule(schedulePayload *SchedulePayload, flightSchedules *FlightSchedules, err error, out string, stdout io.Writer, stderr io.Writer) int {
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		if isInputError(err) {
+			return EXIT_INPUT
+		}
+		return EXIT_FAILURE
+	}
+
+	for _, warning := range schedulePayload.Warnings {
+		fmt.Fprintln(stderr, "warning:", warning)
+	}
+
+	fmt.Fprintf(stdout, "Wrote %d flights to %s\n", len(flightSchedules.Flights), out)
+	if len(flightSchedules.Unfilled) > 0 {
+		for _, seat := range flightSchedules.Unfilled {
+			fmt.Fprintln(stdout, "Unable to fill", seat)
+			for _, exclusion := range seat.Exclusions {
+				fmt.Fprintln(stdout, "   ", exclusion)
+			}
+		}
+		return EXIT_UNFILLED
+	}
+
+	return EXIT_OK
+}
+
+func parseFlightCounts(value string, startDate time.Time) ([]int, error) {
+	if value == "" {
+		flightsPerDay := make([]int, DEFAULT_PLANNING_DAYS)
+		for i := range flightsPerDay {
+			flightsPerDay[i] = flightConfig.defaultNormalFlights(startDate.AddDate(0, 0, i))
+		}
+		return flightsPerDay, nil
+	}
+
+	flightsPerDay := []int{}
+	for _, field := range strings.Split(value, ",") {
+		flights, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || flights < 0 {
+			return nil, fmt.Errorf("%q is not a number of flights", field)
+		}
+		flightsPerDay = append(flightsPerDay, flights)
+	}
+
+	if len(flightsPerDay) > MAX_PLANNING_DAYS {
+		return nil, fmt.Errorf("schedules can cover at most %d days", MAX_PLANNING_DAYS)
+	}
+
+	return flightsPerDay, nil
+}
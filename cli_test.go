@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReportScheduleExitCodes(t *testing.T) {
+	var (
+		flight = &Flight{Type: "MAINTENANCE", Date: "Mar 02 26", Time: "0900"}
+		filled = &FlightSchedules{Flights: []*Flight{flight}}
+		gaps   = &FlightSchedules{
+			Flights:  []*Flight{flight},
+			Unfilled: []*UnfilledSeat{{FlightIndex: 0, Date: flight.Date, Time: flight.Time, Type: flight.Type, Status: "PI"}},
+		}
+		pin = &Pin{Source: "--pin", FirstName: "Jane", LastName: "Doe", Date: "Mar 02 26", Time: "0900"}
+	)
+
+	tests := []struct {
+		name            string
+		flightSchedules *FlightSchedules
+		err             error
+		want            int
+	}{
+		{name: "written", flightSchedules: filled, want: EXIT_OK},
+		{name: "unfilled seats", flightSchedules: gaps, want: EXIT_UNFILLED},
+		{name: "unexpected", err: fmt.Errorf("Error writing out.xlsx: disk full"), want: EXIT_FAILURE},
+		{name: "file not found", err: &FileNotFoundError{File: "Troop to Task.xlsx"}, want: EXIT_INPUT},
+		{name: "sheet missing", err: &SheetMissingError{File: "Troop to Task.xlsx", Sheet: "Troop to Task"}, want: EXIT_INPUT},
+		{name: "header", err: &HeaderError{File: "Troop to Task.xlsx", Row: 1, Col: 4, Problem: "not a date"}, want: EXIT_INPUT},
+		{name: "cell", err: &CellError{File: "Crew.xlsx", Row: 3, Col: 2, Problem: "not a number of hours"}, want: EXIT_INPUT},
+		{name: "pin", err: &PinError{Pin: pin, Problem: "no such flight"}, want: EXIT_INPUT},
+		{name: "json", err: &JSONError{File: "week.json", Path: "crew[0].status", Problem: "is missing"}, want: EXIT_INPUT},
+		{name: "validation", err: &ValidationError{Problems: []error{&JSONError{File: "week.json", Problem: "is empty"}}}, want: EXIT_INPUT},
+		{name: "wrapped", err: fmt.Errorf("replanning: %w", &FileNotFoundError{File: "old.xlsx"}), want: EXIT_INPUT},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			got := reportSchedule(NewSchedulePayload(nil), test.flightSchedules, test.err, "out.xlsx", &stdout, &stderr)
+			if got != test.want {
+				t.Errorf("exit code = %d, want %d; stderr: %s", got, test.want, stderr.String())
+			}
+			if test.err != nil && !strings.Contains(stderr.String(), test.err.Error()) {
+				t.Errorf("stderr = %q, want it to report %q", stderr.String(), test.err)
+			}
+		})
+	}
+}
+
+func TestRunCommandUsageExitCodes(t *testing.T) {
+	tests := [][]string{
+		{"frobnicate"},
+		{"generate", "--layout", "grid"},
+		{"generate", "--history-weeks", "-1"},
+		{"generate", "extra"},
+		{"generate", "--start", "2026-03-02"},
+		{"schema", "roster"},
+	}
+
+	for _, args := range tests {
+		t.Run(strings.Join(args, " "), func(t *testing.T) {
+			if got := runCommand(args); got != EXIT_USAGE {
+				t.Errorf("exit code = %d, want %d", got, EXIT_USAGE)
+			}
+		})
+	}
+}
+
+func TestParseFlightCounts(t *testing.T) {
+	start := time.Date(2026, time.March, 2, 0, 0, 0, 0, time.UTC)
+
+	got, err := parseFlightCounts(" 2, 0,3", start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{2, 0, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("parseFlightCounts = %v, want %v", got, want)
+	}
+
+	tooMany := strings.Repeat("1,", MAX_PLANNING_DAYS) + "1"
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "2,two", want: `"two" is not a number of flights`},
+		{value: "2,,3", want: `"" is not a number of flights`},
+		{value: "-1", want: `"-1" is not a number of flights`},
+		{value: "1.5", want: `"1.5" is not a number of flights`},
+		{value: tooMany, want: fmt.Sprintf("schedules can cover at most %d days", MAX_PLANNING_DAYS)},
+	}
+	for _, test := range tests {
+		if _, err := parseFlightCounts(test.value, start); err == nil || err.Error() != test.want {
+			t.Errorf("parseFlightCounts(%q) error = %v, want %q", test.value, err, test.want)
+		}
+	}
+}
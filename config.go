@@ -0,0 +1,523 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	FLIGHT_CONFIG_FILE = "flights.json"
+	FLIGHT_TIME_FORMAT = "1504"
+	DEFAULT_DAY        = "default"
+)
+
+var flightConfig = defaultFlightConfig()
+
+/*
+Flight templates and the mix of flights flown each day, e.g.
+
+	{
+		"templates": {
+			"maintenance": {"type": "MAINTENANCE", "start": "0900", "duration": "2h", "requires": {"PC": ["MTP"]}},
+			"normal": {"type": "NORMAL", "start": "1200", "duration": "3h"},
+			"normal-short": {"type": "NORMAL", "start": "1700", "duration": "2h", "crew": {"seats": {"PC": {"min": 1, "max": 1}, "PI": {"min": 1, "max": 1}}}}
+		},
+		"crew": {
+			"MAINTENANCE": {"seats": {"PC": {"min": 1, "max": 1}, "PI": {"min": 1, "max": 2}, "FE": {"min": 1, "max": 1}}},
+			"TRAINING": {"seats": {"PC": {"min": 1, "max": 1}, "FE": {"min": 1, "max": 1}, "CE": {"min": 2, "max": 3}}},
+			"NORMAL": {"seats": {"PC": {"min": 1, "max": 1}, "PI": {"min": 1, "max": 2}, "FE": {"min": 1, "max": 1}, "CE": {"min": 1, "max": 3}}}
+		},
+		"days": {
+			"default": ["maintenance"],
+			"Sunday": []
+		},
+		"normal": ["normal", "normal-short"],
+		"default_normal": {"default": 3, "Saturday": 1, "Sunday": 0},
+		"availability": {"": {}, "F": {}, "AMR": {}, "AM": {"until": "1200"}},
+		"rest": {"min_hours": 12, "max_consecutive_days": 5},
+		"duty_day": {"default": {"max_flights": 1}, "CE": {"max_flights": 2, "combinations": [["TRAINING", "NORMAL"]]}},
+		"substitutions": {"PI": {"PC": 30}}
+	}
+
+Every day gets the flights listed under its weekday (or "default"), followed by however many normal flights
+were picked for it. Normal flights use the "normal" templates in order; once those run out the last one is
+repeated, each repeat starting when the previous one ends, as long as it starts before midnight. A template's
+"requires" lists the qualifications crew of each status need to fly it, like the maintenance test pilot PC above.
+"default_normal" is the number of normal flights a day starts out with before it's changed by hand. The crew each
+flight type needs is described by its CrewRule, keyed by type under "crew", unless a template gives its own under
+"crew" like "normal-short" above, and when crew with each Troop to Task code can fly by its AvailabilityWindow
+under "availability". The rest crew need between days is its RestPolicy under "rest", how much they can fly in a
+day their DutyDayPolicy under "duty_day", and which seats crew of another status can fill the Substitutions under
+"substitutions". Only "templates", "days" and "normal" are required; the rest fall back to the defaults noted on
+FlightConfig.
+
+A config file ending in .yaml or .yml holds the same fields as YAML, e.g.
+
+	templates:
+	  maintenance: {type: MAINTENANCE, start: "0900", duration: 2h, requires: {PC: [MTP]}}
+	  normal: {type: NORMAL, start: "1200", duration: 3h}
+	days: {default: [maintenance], Sunday: []}
+	normal: [normal]
+	rest: {min_hours: 12}
+
+Times need quoting there, or YAML reads 0900 as a number. Keys that aren't fields of the config are an error in
+either format.
+*/
+type FlightConfig struct {
+	Templates     map[string]*FlightTemplate     `json:"templates"`
+	Days          map[string][]string            `json:"days"`
+	Normal        []string                       `json:"normal"`
+	DefaultNormal map[string]int                 `json:"default_normal"` //Key: weekday or "default"; Value: normal flights; 3 a day if not given
+	Crew          map[string]*CrewRule           `json:"crew"`           //Key: flight type; the built-in rules if not given
+	Availability  map[string]*AvailabilityWindow `json:"availability"`   //Key: Troop to Task code; blank, F and AMR if not given
+	Rest          *RestPolicy                    `json:"rest"`           //12 hours between flights if not given
+	DutyDay       map[string]*DutyDayPolicy      `json:"duty_day"`       //Key: crew status, "Last, First" or "default"; one flight a day if not given
+	Substitutions Substitutions                  `json:"substitutions,omitempty"`
+}
+
+type FlightTemplate struct {
+	Type     string              `json:"type"`               //MAINTENANCE, TRAINING, or NORMAL
+	Start    string              `json:"start"`              //24 hour clock, e.g. 0900
+	Duration string              `json:"duration"`           //e.g. 1h30m
+	Requires map[string][]string `json:"requires,omitempty"` //Key: crew status; Value: qualifications from info.xlsx, e.g. MTP
+	Crew     *CrewRule           `json:"crew,omitempty"`     //Overrides the rule for its type under "crew"
+
+	start    time.Time
+	duration time.Duration
+}
+
+func defaultFlightConfig() *FlightConfig {
+	config := &FlightConfig{
+		Templates: map[string]*FlightTemplate{
+			"maintenance": {
+				Type:     "MAINTENANCE",
+				Start:    "0900",
+				Duration: "2h",
+			},
+			"training-0800": {
+				Type:     "TRAINING",
+				Start:    "0800",
+				Duration: "1h",
+			},
+			"training-1000": {
+				Type:     "TRAINING",
+				Start:    "1000",
+				Duration: "1h",
+			},
+			"training-1100": {
+				Type:     "TRAINING",
+				Start:    "1100",
+				Duration: "1h",
+			},
+			"normal-1200": {
+				Type:     "NORMAL",
+				Start:    "1200",
+				Duration: "3h",
+			},
+			"normal-1700": {
+				Type:     "NORMAL",
+				Start:    "1700",
+				Duration: "3h",
+			},
+		},
+		Days: map[string][]string{
+			DEFAULT_DAY: {"maintenance", "training-0800", "training-1000", "training-1100"},
+		},
+		Normal:        []string{"normal-1200", "normal-1200", "normal-1700"},
+		DefaultNormal: map[string]int{DEFAULT_DAY: DEFAULT_FLIGHTS_PER_DAY},
+		Crew:          defaultCrewRules(),
+		Availability:  defaultAvailabilityCodes(),
+		Rest:          defaultRestPolicy(),
+		DutyDay:       defaultDutyDayPolicies(),
+	}
+
+	fatalIf(config.validate())
+
+	return config
+}
+
+// Reads a flight config, as YAML if the file name ends in .yaml or .yml and JSON otherwise; the built-in one is
+// used when the default config file doesn't exist
+func loadFlightConfig(fileName string) (*FlightConfig, error) {
+	data, err := os.ReadFile(fileName)
+	if os.IsNotExist(err) && fileName == FLIGHT_CONFIG_FILE {
+		return defaultFlightConfig(), nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if isYAMLFile(fileName) {
+		if data, err = yamlToJSON(data); err != nil {
+			return nil, fmt.Errorf("Error parsing %s: %s", fileName, err)
+		}
+	}
+
+	config := &FlightConfig{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(config); err != nil {
+		return nil, fmt.Errorf("Error parsing %s: %s", fileName, err)
+	}
+
+	if err := config.validate(); err != nil {
+		return nil, fmt.Errorf("Error in %s: %s", fileName, err)
+	}
+
+	return config, nil
+}
+
+func isYAMLFile(fileName string) bool {
+	ext := strings.ToLower(filepath.Ext(fileName))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// Re-encodes a YAML document as JSON, so YAML configs are decoded, defaulted and validated just like JSON ones,
+// unknown keys included
+func yamlToJSON(data []byte) ([]byte, error) {
+	var document interface{}
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(document)
+}
+
+func (c *FlightConfig) validate() error {
+	for name, template := range c.Templates {
+		if template == nil {
+			return fmt.Errorf("template %q is empty", name)
+		}
+
+		if !isFlightType(template.Type) {
+			return fmt.Errorf("template %q has unknown type %q", name, template.Type)
+		}
+
+		start, err := time.Parse(FLIGHT_TIME_FORMAT, template.Start)
+		if err != nil {
+			return fmt.Errorf("template %q has start %q; expected a time like 0900", name, template.Start)
+		}
+		template.start = start
+
+		duration, err := time.ParseDuration(template.Duration)
+		if err != nil || duration <= 0 {
+			return fmt.Errorf("template %q has duration %q; expected a duration like 1h30m", name, template.Duration)
+		}
+		template.duration = duration
+
+		for status, qualifications := range template.Requires {
+			if !isCrewStatus(status) {
+				return fmt.Errorf("template %q requires qualifications of unknown crew status %q", name, status)
+			}
+			for i, qualification := range qualifications {
+				if qualificationName(qualification) == "" {
+					return fmt.Errorf("template %q has a blank qualification for %s", name, status)
+				}
+				qualifications[i] = qualificationName(qualification)
+			}
+		}
+
+		if template.Crew != nil {
+			if err := template.Crew.validate(fmt.Sprintf("templates.%s.crew", name)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if _, ok := c.Days[DEFAULT_DAY]; !ok {
+		return fmt.Errorf("days has no %q entry", DEFAULT_DAY)
+	}
+
+	for day, templateNames := range c.Days {
+		if day != DEFAULT_DAY {
+			if _, err := parseWeekday(day); err != nil {
+				return err
+			}
+		}
+
+		for _, name := range templateNames {
+			if _, ok := c.Templates[name]; !ok {
+				return fmt.Errorf("days.%s uses unknown template %q", day, name)
+			}
+		}
+	}
+
+	if len(c.Normal) == 0 {
+		return fmt.Errorf("normal needs at least one template")
+	}
+	for _, name := range c.Normal {
+		if _, ok := c.Templates[name]; !ok {
+			return fmt.Errorf("normal uses unknown template %q", name)
+		}
+	}
+
+	if c.DefaultNormal == nil {
+		c.DefaultNormal = map[string]int{DEFAULT_DAY: DEFAULT_FLIGHTS_PER_DAY}
+	}
+	if _, ok := c.DefaultNormal[DEFAULT_DAY]; !ok {
+		c.DefaultNormal[DEFAULT_DAY] = DEFAULT_FLIGHTS_PER_DAY
+	}
+	for day, flights := range c.DefaultNormal {
+		if day != DEFAULT_DAY {
+			if _, err := parseWeekday(day); err != nil {
+				return err
+			}
+		}
+		if flights < 0 {
+			return fmt.Errorf("default_normal.%s is negative", day)
+		}
+		if _, err := c.repeatTemplates(day, c.Normal, flights); err != nil {
+			return fmt.Errorf("default_normal.%s: %s", day, err)
+		}
+	}
+
+	if c.Availability == nil {
+		c.Availability = defaultAvailabilityCodes()
+	}
+	if err := validateAvailabilityCodes(c.Availability); err != nil {
+		return err
+	}
+
+	if c.Rest == nil {
+		c.Rest = defaultRestPolicy()
+	}
+	if err := c.Rest.validate(); err != nil {
+		return err
+	}
+
+	if c.DutyDay == nil {
+		c.DutyDay = defaultDutyDayPolicies()
+	}
+	if err := validateDutyDayPolicies(c.DutyDay); err != nil {
+		return err
+	}
+
+	if err := c.Substitutions.validate(); err != nil {
+		return err
+	}
+
+	if c.Crew == nil {
+		c.Crew = defaultCrewRules()
+	}
+	return validateCrewRules(c.Crew)
+}
+
+// Templates flown every day on the date's weekday
+func (c *FlightConfig) dayTemplates(date time.Time) []string {
+	if templateNames, ok := c.Days[date.Weekday().String()]; ok {
+		return templateNames
+	}
+	return c.Days[DEFAULT_DAY]
+}
+
+// Number of normal flights a day gets unless it's changed by hand
+func (c *FlightConfig) defaultNormalFlights(date time.Time) int {
+	if flights, ok := c.DefaultNormal[date.Weekday().String()]; ok {
+		return flights
+	}
+	return c.DefaultNormal[DEFAULT_DAY]
+}
+
+// Key: flight type; Value: number of the day's fixed (maintenance and training) flights of that type
+func (c *FlightConfig) defaultFixedFlights(date time.Time) map[string]int {
+	fixedFlights := map[string]int{"MAINTENANCE": 0, "TRAINING": 0}
+	for _, name := range c.dayTemplates(date) {
+		fixedFlights[c.Templates[name].Type]++
+	}
+	return fixedFlights
+}
+
+// Flights for a single day: the weekday's fixed flights followed by the given number of normal flights. When
+// fixedFlights is set, it overrides how many maintenance and training flights the weekday has
+func (c *FlightConfig) flightsForDay(date time.Time, normalFlights int, fixedFlights map[string]int) ([]*Flight, error) {
+	var (
+		flights       = []*Flight{}
+		templateNames = c.dayTemplates(date)
+		fullDate      = date.Format(FULL_DATE_FORMAT)
+	)
+
+	if fixedFlights == nil {
+		fixed, err := c.repeatTemplates(fullDate, templateNames, len(templateNames))
+		if err != nil {
+			return nil, fmt.Errorf("Can't schedule %s: %s", date.Format("Mon Jan 2"), err)
+		}
+		flights = append(flights, fixed...)
+	} else {
+		for _, flightType := range []string{"MAINTENANCE", "TRAINING"} {
+			pool := []string{}
+			for _, name := range templateNames {
+				if c.Templates[name].Type == flightType {
+					pool = append(pool, name)
+				}
+			}
+			if len(pool) == 0 {
+				pool = c.templatesOfType(flightType)
+			}
+
+			if fixedFlights[flightType] > 0 && len(pool) == 0 {
+				return nil, fmt.Errorf("No %s flight template to schedule %s from", flightType, date.Format("Mon Jan 2"))
+			}
+			repeated, err := c.repeatTemplates(fullDate, pool, fixedFlights[flightType])
+			if err != nil {
+				return nil, fmt.Errorf("Can't schedule %s: %s", date.Format("Mon Jan 2"), err)
+			}
+			flights = append(flights, repeated...)
+		}
+	}
+
+	normal, err := c.repeatTemplates(fullDate, c.Normal, normalFlights)
+	if err != nil {
+		return nil, fmt.Errorf("Can't schedule %s: %s", date.Format("Mon Jan 2"), err)
+	}
+	flights = append(flights, normal...)
+
+	return flights, nil
+}
+
+// Flights from the named templates in order; once those run out the last one is repeated, each repeat starting
+// when the previous one ends. Repeats that would start after midnight are an error rather than being moved onto
+// the next day, where they'd be crewed as that day's early flights
+func (c *FlightConfig) repeatTemplates(fullDate string, templateNames []string, count int) ([]*Flight, error) {
+	flights := []*Flight{}
+
+	for i := 0; i < count; i++ {
+		if i < len(templateNames) {
+			template := c.Templates[templateNames[i]]
+			flights = append(flights, template.newFlight(fullDate, template.start))
+			continue
+		}
+
+		name := templateNames[len(templateNames)-1]
+		start, ok := c.Templates[name].repeatStart(i - len(templateNames) + 1)
+		if !ok {
+			return nil, fmt.Errorf("%d flights from %s don't fit in a day; repeats of template %q after the %s one would start after midnight",
+				count, strings.Join(templateNames, ", "), name, flights[len(flights)-1].Time)
+		}
+		flights = append(flights, c.Templates[name].newFlight(fullDate, start))
+	}
+
+	return flights, nil
+}
+
+// Start of the given repeat of the template, and whether it's still before midnight
+func (t *FlightTemplate) repeatStart(repeat int) (time.Time, bool) {
+	start := t.start.Add(time.Duration(repeat) * t.duration)
+	return start, start.YearDay() == t.start.YearDay()
+}
+
+// Names of every template of the given type, earliest first
+func (c *FlightConfig) templatesOfType(flightType string) []string {
+	names := []string{}
+	for name, template := range c.Templates {
+		if template.Type == flightType {
+			names = append(names, name)
+		}
+	}
+
+	sort.Slice(names, func(a, b int) bool {
+		startA, startB := c.Templates[names[a]].start, c.Templates[names[b]].start
+		if startA.Equal(startB) {
+			return names[a] < names[b]
+		}
+		return startA.Before(startB)
+	})
+
+	return names
+}
+
+func (t *FlightTemplate) newFlight(fullDate string, start time.Time) *Flight {
+	return &Flight{
+		Type:     t.Type,
+		Date:     fullDate,
+		Time:     start.Format(FLIGHT_TIME_FORMAT),
+		Duration: t.duration,
+		Requires: t.Requires,
+		Rule:     t.Crew,
+	}
+}
+
+// Template the flight was made from, or one it was repeated from; a template of the same type if neither starts
+// at that time, or nil if there are none of the type
+func (c *FlightConfig) flightTemplate(flightType string, flightTime string) *FlightTemplate {
+	var first, repeated *FlightTemplate
+
+	for _, name := range c.templatesOfType(flightType) {
+		template := c.Templates[name]
+		if template.start.Format(FLIGHT_TIME_FORMAT) == flightTime {
+			return template
+		}
+		if repeated == nil && template.repeats(flightTime) {
+			repeated = template
+		}
+		if first == nil {
+			first = template
+		}
+	}
+
+	if repeated != nil {
+		return repeated
+	}
+	return first
+}
+
+// Whether a repeat of the template starts at the time
+func (t *FlightTemplate) repeats(flightTime string) bool {
+	for repeat := 1; ; repeat++ {
+		start, ok := t.repeatStart(repeat)
+		if !ok {
+			return false
+		}
+		if start.Format(FLIGHT_TIME_FORMAT) == flightTime {
+			return true
+		}
+	}
+}
+
+// Duration of the template the flight was made from
+func (c *FlightConfig) flightDuration(flightType string, flightTime string) time.Duration {
+	if template := c.flightTemplate(flightType, flightTime); template != nil {
+		return template.duration
+	}
+	return 0
+}
+
+// Crew the template the flight was made from needs, or nil if it uses its type's rule
+func (c *FlightConfig) flightCrewRule(flightType string, flightTime string) *CrewRule {
+	if template := c.flightTemplate(flightType, flightTime); template != nil {
+		return template.Crew
+	}
+	return nil
+}
+
+// Qualifications the template the flight was made from requires
+func (c *FlightConfig) flightRequirements(flightType string, flightTime string) map[string][]string {
+	if template := c.flightTemplate(flightType, flightTime); template != nil {
+		return template.Requires
+	}
+	return nil
+}
+
+func isCrewStatus(status string) bool {
+	for _, crewStatus := range seatStatuses {
+		if status == crewStatus {
+			return true
+		}
+	}
+	return false
+}
+
+func parseWeekday(name string) (time.Weekday, error) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if day.String() == name {
+			return day, nil
+		}
+	}
+	return 0, fmt.Errorf("%q is not a weekday; expected Sunday through Saturday or %q", name, DEFAULT_DAY)
+}
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFlightsForDay(t *testing.T) {
+	tests := []struct {
+		name          string
+		normalFlights int
+		wantTimes     []string
+		wantErr       bool
+	}{
+		{
+			name:          "default normal flights",
+			normalFlights: 3,
+			wantTimes:     []string{"0900", "0800", "1000", "1100", "1200", "1200", "1700"},
+		},
+		{
+			name:          "repeats of the last template until 2300",
+			normalFlights: 5,
+			wantTimes:     []string{"0900", "0800", "1000", "1100", "1200", "1200", "1700", "2000", "2300"},
+		},
+		{
+			// The repeat after 2300 would start at 0200, which is the next morning
+			name:          "repeat past midnight",
+			normalFlights: 6,
+			wantErr:       true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			flights, err := flightConfig.flightsForDay(time.Date(2026, time.January, 5, 0, 0, 0, 0, time.UTC), test.normalFlights, nil)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("err = nil, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			times := []string{}
+			for _, flight := range flights {
+				times = append(times, flight.Time)
+				if flight.Date != "Jan 05 26" {
+					t.Errorf("%s flight is on %s, want Jan 05 26", flight.Time, flight.Date)
+				}
+			}
+			if !reflect.DeepEqual(times, test.wantTimes) {
+				t.Errorf("times = %v, want %v", times, test.wantTimes)
+			}
+		})
+	}
+}
+
+func TestValidateDefaultNormalPastMidnight(t *testing.T) {
+	config := defaultFlightConfig()
+	config.DefaultNormal = map[string]int{DEFAULT_DAY: 3, "Saturday": 7}
+
+	if err := config.validate(); err == nil {
+		t.Errorf("err = nil, want an error for Saturday's repeats past midnight")
+	}
+}
+
+func TestFlightsForDayWithFixedFlights(t *testing.T) {
+	config := defaultFlightConfig()
+	config.Days["Sunday"] = []string{}
+
+	tests := []struct {
+		name         string
+		date         time.Time
+		fixedFlights map[string]int
+		wantTimes    []string
+	}{
+		{
+			name:         "more maintenance and less training than the weekday",
+			date:         time.Date(2026, time.January, 5, 0, 0, 0, 0, time.UTC),
+			fixedFlights: map[string]int{"MAINTENANCE": 2, "TRAINING": 1},
+			wantTimes:    []string{"0900", "1100", "0800", "1200"},
+		},
+		{
+			// Sunday has no templates of its own, so its flights come from every template of the type
+			name:         "weekday without fixed flights",
+			date:         time.Date(2026, time.January, 4, 0, 0, 0, 0, time.UTC),
+			fixedFlights: map[string]int{"TRAINING": 2},
+			wantTimes:    []string{"0800", "1000", "1200"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			flights, err := config.flightsForDay(test.date, 1, test.fixedFlights)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			times := []string{}
+			for _, flight := range flights {
+				times = append(times, flight.Time)
+			}
+			if !reflect.DeepEqual(times, test.wantTimes) {
+				t.Errorf("times = %v, want %v", times, test.wantTimes)
+			}
+		})
+	}
+}
+
+func TestFlightsForDayWithoutATemplateOfTheType(t *testing.T) {
+	config := defaultFlightConfig()
+	for _, name := range config.templatesOfType("TRAINING") {
+		delete(config.Templates, name)
+	}
+	config.Days = map[string][]string{DEFAULT_DAY: {"maintenance"}}
+
+	_, err := config.flightsForDay(time.Date(2026, time.January, 5, 0, 0, 0, 0, time.UTC), 0, map[string]int{"TRAINING": 1})
+	if want := "No TRAINING flight template to schedule Mon Jan 5 from"; err == nil || err.Error() != want {
+		t.Errorf("err = %v, want %q", err, want)
+	}
+}
+
+func TestDefaultNormalFlights(t *testing.T) {
+	config := defaultFlightConfig()
+	config.DefaultNormal = map[string]int{"Saturday": 1, "Sunday": 0}
+	if err := config.validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	got := []int{}
+	for day := 3; day <= 5; day++ {
+		got = append(got, config.defaultNormalFlights(time.Date(2026, time.January, day, 0, 0, 0, 0, time.UTC)))
+	}
+	if want := []int{1, 0, DEFAULT_FLIGHTS_PER_DAY}; !reflect.DeepEqual(got, want) {
+		t.Errorf("default normal flights Saturday to Monday = %v, want %v", got, want)
+	}
+
+	for _, defaultNormal := range []map[string]int{{"Funday": 1}, {"Monday": -1}} {
+		config := defaultFlightConfig()
+		config.DefaultNormal = defaultNormal
+		if err := config.validate(); err == nil {
+			t.Errorf("validate() with default_normal %v = nil, want an error", defaultNormal)
+		}
+	}
+}
+
+// The examples in the FlightConfig doc comment
+func TestLoadFlightConfigExamples(t *testing.T) {
+	examples := map[string]string{
+		"flights.json": `{
+	"templates": {
+		"maintenance": {"type": "MAINTENANCE", "start": "0900", "duration": "2h", "requires": {"PC": ["MTP"]}},
+		"normal": {"type": "NORMAL", "start": "1200", "duration": "3h"}
+	},
+	"crew": {
+		"MAINTENANCE": {"seats": {"PC": {"min": 1, "max": 1}, "PI": {"min": 1, "max": 2}, "FE": {"min": 1, "max": 1}}},
+		"TRAINING": {"seats": {"PC": {"min": 1, "max": 1}, "FE": {"min": 1, "max": 1}, "CE": {"min": 2, "max": 3}}},
+		"NORMAL": {"seats": {"PC": {"min": 1, "max": 1}, "PI": {"min": 1, "max": 2}, "FE": {"min": 1, "max": 1}, "CE": {"min": 1, "max": 3}}}
+	},
+	"days": {
+		"default": ["maintenance"],
+		"Sunday": []
+	},
+	"normal": ["normal"],
+	"default_normal": {"default": 3, "Saturday": 1, "Sunday": 0},
+	"availability": {"": {}, "F": {}, "AMR": {}, "AM": {"until": "1200"}},
+	"rest": {"min_hours": 12, "max_consecutive_days": 5},
+	"duty_day": {"default": {"max_flights": 1}, "CE": {"max_flights": 2, "combinations": [["TRAINING", "NORMAL"]]}},
+	"substitutions": {"PI": {"PC": 30}}
+}`,
+		"flights.yaml": `templates:
+  maintenance: {type: MAINTENANCE, start: "0900", duration: 2h, requires: {PC: [MTP]}}
+  normal: {type: NORMAL, start: "1200", duration: 3h}
+days: {default: [maintenance], Sunday: []}
+normal: [normal]
+rest: {min_hours: 12}
+`,
+	}
+
+	for fileName, config := range examples {
+		t.Run(fileName, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), fileName)
+			if err := os.WriteFile(path, []byte(config), 0600); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := loadFlightConfig(path); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestLoadFlightConfig(t *testing.T) {
+	const templates = `"templates": {
+		"normal": {"type": "NORMAL", "start": "1200", "duration": "3h"},
+		"short": {"type": "NORMAL", "start": "1500", "duration": "2h", "crew": {"seats": {"PC": {"min": 1, "max": 1}, "PI": {"min": 1, "max": 1}}}}
+	}`
+
+	tests := []struct {
+		name      string
+		fileName  string
+		config    string
+		wantSeats []map[string]int // Seats each of a day's flights needs
+		wantErr   bool
+	}{
+		{
+			name:     "template crew overrides its type's",
+			fileName: "flights.json",
+			config:   `{` + templates + `, "days": {"default": []}, "normal": ["normal", "short"]}`,
+			wantSeats: []map[string]int{
+				{"PC": 1, "PI": 1, "FE": 1, "CE": 1},
+				{"PC": 1, "PI": 1},
+				{"PC": 1, "PI": 1},
+			},
+		},
+		{
+			// Templates had a seat count per status before crew rules replaced it
+			name:     "stale seats on a template",
+			fileName: "flights.json",
+			config:   `{"templates": {"normal": {"type": "NORMAL", "start": "1200", "duration": "3h", "seats": {"PC": 1}}}, "days": {"default": []}, "normal": ["normal"]}`,
+			wantErr:  true,
+		},
+		{
+			name:     "misspelled key in YAML",
+			fileName: "flights.yaml",
+			config:   "templates:\n  normal: {type: NORMAL, start: \"1200\", duration: 3h}\ndays: {default: []}\nnormal: [normal]\ndefault_normals: {default: 2}\n",
+			wantErr:  true,
+		},
+		{
+			name:     "template crew without required seats",
+			fileName: "flights.json",
+			config:   `{"templates": {"normal": {"type": "NORMAL", "start": "1200", "duration": "3h", "crew": {"seats": {"PC": {"min": 0, "max": 1}}}}}, "days": {"default": []}, "normal": ["normal"]}`,
+			wantErr:  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fileName := filepath.Join(t.TempDir(), test.fileName)
+			if err := os.WriteFile(fileName, []byte(test.config), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			config, err := loadFlightConfig(fileName)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("err = nil, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			saved := flightConfig
+			flightConfig = config
+			defer func() { flightConfig = saved }()
+
+			flights, err := config.flightsForDay(time.Date(2026, time.January, 5, 0, 0, 0, 0, time.UTC), len(test.wantSeats), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			seats := []map[string]int{}
+			for _, flight := range flights {
+				required := make(map[string]int)
+				for status, seatRange := range flight.crewRule().compositions()[0] {
+					required[status] = seatRange.Min
+				}
+				seats = append(seats, required)
+			}
+			if !reflect.DeepEqual(seats, test.wantSeats) {
+				t.Errorf("seats = %v, want %v", seats, test.wantSeats)
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/tealeg/xlsx"
+)
+
+var gapsSheetHeading = []string{
+	"Date",
+	"Flight Type",
+	"Time",
+	"Status",
+	"Rank",
+	"First Name",
+	"Last Name",
+	"Reason",
+}
+
+type Exclusion struct {
+	Crew   *CrewMember // Nil when there's nobody with the seat's status
+	Reason string
+}
+
+func (e *Exclusion) String() string {
+	if e.Crew == nil {
+		return e.Reason
+	}
+	return fmt.Sprintf("%s %s %s: %s", e.Crew.Rank, e.Crew.FirstName, e.Crew.LastName, e.Reason)
+}
+
+// Fills in Exclusions for every unfilled seat, checking each crew member of the seat's status, and of any
+// status the substitutions let fill it, against the final roster
+func explainUnfilledSeats(s *SchedulePayload, flightSchedules *FlightSchedules) {
+	flightsByCrew := crewFlightsByDate(flightSchedules)
+
+	for _, seat := range flightSchedules.Unfilled {
+		seat.Exclusions = []*Exclusion{}
+
+		for _, crew := range s.CrewAvailability {
+			if _, ok := flightConfig.Substitutions.penalty(seat.Status, crew.Status); !ok {
+				continue
+			}
+
+			reason := exclusionReason(crew, seat, flightSchedules, flightsByCrew)
+			if crew.Status != seat.Status {
+				reason = fmt.Sprintf("%s substitute, %s", crew.Status, reason)
+			}
+			seat.Exclusions = append(seat.Exclusions, &Exclusion{
+				Crew:   NewCrewMember(crew),
+				Reason: reason,
+			})
+		}
+
+		if len(seat.Exclusions) == 0 {
+			seat.Exclusions = append(seat.Exclusions, &Exclusion{
+				Reason: fmt.Sprintf("no %ss in %s", seat.Status, s.fileName),
+			})
+		}
+	}
+}
+
+func exclusionReason(crew *CrewAvailability, seat *UnfilledSeat, flightSchedules *FlightSchedules, flightsByCrew map[string]map[string][]*Flight) string {
+	if reason := crew.unavailableReason(flightSchedules.Flights[seat.FlightIndex]); reason != "" {
+		return reason
+	}
+	if reason := crew.unqualifiedReasonFor(flightSchedules.Flights[seat.FlightIndex], seat.Status); reason != "" {
+		return reason
+	}
+
+	var (
+		flight  = flightSchedules.Flights[seat.FlightIndex]
+		flights = flightsByCrew[crewName(crew.FirstName, crew.LastName)]
+		policy  = flightConfig.dutyDayPolicy(crew.FirstName, crew.LastName, crew.Status)
+	)
+	if reason := policy.violation(flights[seat.Date], flight); reason != "" {
+		return reason
+	}
+
+	duties := []*Flight{}
+	for _, dayFlights := range flights {
+		duties = append(duties, dayFlights...)
+	}
+	if reason := flightConfig.Rest.violation(duties, flight); reason != "" {
+		return reason
+	}
+
+	if flightSchedules.Pairing != nil {
+		keys, isInstructor := flightPairingKeys(flightSchedules, flight)
+		if reason := flightSchedules.Pairing.violation(hoursKey(crew.FirstName, crew.LastName), keys, isInstructor); reason != "" {
+			return reason
+		}
+	}
+
+	if flightSchedules.Reviewed {
+		return "available, but left out when the schedule was reviewed"
+	}
+	for _, day := range flightSchedules.CutOff {
+		if day.Date == seat.Date {
+			return "available, but the search was cut off before they were seated"
+		}
+	}
+	// Filling a seat always beats leaving it empty, so a finished search never leaves out crew who could sit in it
+	return "available and allowed in the seat, but left out by the solver; please report this as a bug"
+}
+
+// Rebuilds FlightSchedules.Unfilled from the crew now seated, after they've been changed by hand
+func findUnfilledSeats(flightSchedules *FlightSchedules) {
+	flightSchedules.Unfilled = []*UnfilledSeat{}
+
+	for i, flight := range flightSchedules.Flights {
+		for _, status := range seatStatuses {
+			for n := 0; n < flight.openSeats(status); n++ {
+				flightSchedules.Unfilled = append(flightSchedules.Unfilled, &UnfilledSeat{
+					FlightIndex: i,
+					Date:        flight.Date,
+					Time:        flight.Time,
+					Type:        flight.Type,
+					Status:      status,
+				})
+			}
+		}
+	}
+}
+
+// Key: crew name; Value: flights by date
+func crewFlightsByDate(flightSchedules *FlightSchedules) map[string]map[string][]*Flight {
+	flightsByCrew := make(map[string]map[string][]*Flight)
+
+	for _, flight := range flightSchedules.Flights {
+		for _, crew := range flight.crew() {
+			name := crewName(crew.FirstName, crew.LastName)
+			if flightsByCrew[name] == nil {
+				flightsByCrew[name] = make(map[string][]*Flight)
+			}
+			flightsByCrew[name][flight.Date] = append(flightsByCrew[name][flight.Date], flight)
+		}
+	}
+
+	return flightsByCrew
+}
+
+func crewName(firstName string, lastName string) string {
+	return fmt.Sprintf("%s %s", firstName, lastName)
+}
+
+// Required seats of the status that nobody is in
+func (f *Flight) openSeats(status string) int {
+	seated := 0
+	for _, crew := range f.crew() {
+		if crew.seatStatus() == status {
+			seated++
+		}
+	}
+
+	if seated >= f.Seats[status] {
+		return 0
+	}
+	return f.Seats[status] - seated
+}
+
+// Everyone seated on the flight, in seat order
+func (f *Flight) crew() []*CrewMember {
+	crew := []*CrewMember{}
+	if f.PC != nil {
+		crew = append(crew, f.PC)
+	}
+	crew = append(crew, f.PIs...)
+	crew = append(crew, f.FEs...)
+	crew = append(crew, f.CEs...)
+
+	return crew
+}
+
+func addGapsSheet(file *xlsx.File, flightSchedules *FlightSchedules) error {
+	sheet, err := file.AddSheet(GAPS_SHEET)
+	if err != nil {
+		return err
+	}
+
+	addSheetHeading(sheet, gapsSheetHeading)
+	for _, seat := range flightSchedules.Unfilled {
+		for _, exclusion := range seat.Exclusions {
+			rank, firstName, lastName := "-", "-", "-"
+			if exclusion.Crew != nil {
+				rank, firstName, lastName = exclusion.Crew.Rank, exclusion.Crew.FirstName, exclusion.Crew.LastName
+			}
+
+			row := sheet.AddRow()
+			for _, value := range []string{seat.Date, seat.Type, seat.Time, seat.Status, rank, firstName, lastName, exclusion.Reason} {
+				cell := row.AddCell()
+				cell.Value = value
+			}
+		}
+	}
+
+	return nil
+}
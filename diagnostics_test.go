@@ -0,0 +1,81 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+const diagnosticsDate = "Mar 02 26"
+
+// PIs on diagnosticsDate with the given Troop to Task codes, blank meaning available all day
+func diagnosticsCrew(codes ...string) []*CrewAvailability {
+	crew := []*CrewAvailability{}
+	for i, code := range codes {
+		crew = append(crew, &CrewAvailability{
+			FirstName:   string(rune('A' + i)),
+			LastName:    "Roe",
+			Rank:        "CW3",
+			Status:      "PI",
+			Availabilty: map[string]bool{diagnosticsDate: code == ""},
+			Codes:       map[string]string{diagnosticsDate: code},
+		})
+	}
+	return crew
+}
+
+func TestExplainUnfilledSeats(t *testing.T) {
+	tests := []struct {
+		name     string
+		reviewed bool
+		cutOff   bool
+		want     string
+	}{
+		{name: "left out by hand", reviewed: true, want: "CW3 A Roe: available, but left out when the schedule was reviewed"},
+		{name: "search cut off", cutOff: true, want: "CW3 A Roe: available, but the search was cut off before they were seated"},
+		{name: "finished search", want: "CW3 A Roe: available and allowed in the seat, but left out by the solver; please report this as a bug"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			crew := diagnosticsCrew("", "L")
+			flight := &Flight{Type: "MAINTENANCE", Date: diagnosticsDate, Time: "0900", Duration: 2 * time.Hour}
+			flightSchedules := &FlightSchedules{
+				Flights:  []*Flight{flight},
+				Unfilled: []*UnfilledSeat{{FlightIndex: 0, Date: flight.Date, Time: flight.Time, Type: flight.Type, Status: "PI"}},
+				Reviewed: test.reviewed,
+			}
+			if test.cutOff {
+				flightSchedules.CutOff = []*CutOffDay{{Date: diagnosticsDate, Unfilled: 1}}
+			}
+
+			explainUnfilledSeats(NewSchedulePayload(crew), flightSchedules)
+
+			reasons := []string{}
+			for _, exclusion := range flightSchedules.Unfilled[0].Exclusions {
+				reasons = append(reasons, exclusion.String())
+			}
+			want := []string{test.want, "CW3 B Roe: unavailable (code L) in Troop to Task"}
+			if !reflect.DeepEqual(reasons, want) {
+				t.Errorf("Exclusions = %q, want %q", reasons, want)
+			}
+		})
+	}
+}
+
+func TestExplainUnfilledSeatsWithNobodyOfTheStatus(t *testing.T) {
+	flight := &Flight{Type: "MAINTENANCE", Date: diagnosticsDate, Time: "0900", Duration: 2 * time.Hour}
+	flightSchedules := &FlightSchedules{
+		Flights:  []*Flight{flight},
+		Unfilled: []*UnfilledSeat{{FlightIndex: 0, Date: flight.Date, Time: flight.Time, Type: flight.Type, Status: "FE"}},
+	}
+
+	s := NewSchedulePayload(diagnosticsCrew(""))
+	s.fileName = "week.json"
+	explainUnfilledSeats(s, flightSchedules)
+
+	exclusions := flightSchedules.Unfilled[0].Exclusions
+	if len(exclusions) != 1 || exclusions[0].String() != "no FEs in week.json" {
+		t.Errorf("Exclusions = %v, want only %q", exclusions, "no FEs in week.json")
+	}
+}
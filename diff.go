@@ -0,0 +1,322 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Everything that differs between two flight schedules
+type ScheduleDiff struct {
+	AddedFlights   []*Flight // Only in the new schedule
+	RemovedFlights []*Flight // Only in the old schedule
+	Changes        []*Change
+	Gaps           []*GapChange
+	Loads          []*LoadChange
+}
+
+func (d *ScheduleDiff) empty() bool {
+	return len(d.AddedFlights) == 0 && len(d.RemovedFlights) == 0 && len(d.Changes) == 0 && len(d.Gaps) == 0 && len(d.Loads) == 0
+}
+
+// Flight with a different number of unfilled seats of a status
+type GapChange struct {
+	Flight *Flight
+	Status string
+	Before int
+	After  int
+}
+
+func (g *GapChange) String() string {
+	change := "gained"
+	n := g.After - g.Before
+	if n < 0 {
+		change, n = "lost", -n
+	}
+	return fmt.Sprintf("%s %s %s: %s %d %s gap(s), now %d", g.Flight.Date, g.Flight.Time, g.Flight.Type, change, n, g.Status, g.After)
+}
+
+// Crew member flying a different number of flights or hours
+type LoadChange struct {
+	Crew        *CrewMember
+	Before      int
+	After       int
+	HoursBefore float64
+	HoursAfter  float64
+}
+
+func (l *LoadChange) String() string {
+	return fmt.Sprintf("%s %s %s %s: %d -> %d flights, %.1f -> %.1f hours", l.Crew.Status, l.Crew.Rank, l.Crew.FirstName, l.Crew.LastName, l.Before, l.After, l.HoursBefore, l.HoursAfter)
+}
+
+// Reads two written schedules, workbooks or JSON, and compares them
+func diffScheduleFiles(oldFileName string, newFileName string) (*ScheduleDiff, error) {
+	oldSchedules, err := readFlightSchedules(oldFileName)
+	if err != nil {
+		return nil, err
+	}
+
+	newSchedules, err := readFlightSchedules(newFileName)
+	if err != nil {
+		return nil, err
+	}
+
+	return diffFlightSchedules(oldSchedules, newSchedules), nil
+}
+
+func diffFlightSchedules(oldSchedules *FlightSchedules, newSchedules *FlightSchedules) *ScheduleDiff {
+	diff := &ScheduleDiff{
+		AddedFlights:   []*Flight{},
+		RemovedFlights: []*Flight{},
+		Changes:        crewChanges(oldSchedules, newSchedules, nil, nil),
+		Gaps:           []*GapChange{},
+		Loads:          loadChanges(oldSchedules, newSchedules),
+	}
+
+	var (
+		oldGaps = gapsByFlight(oldSchedules)
+		newGaps = gapsByFlight(newSchedules)
+	)
+
+	for _, pair := range matchFlights(oldSchedules, newSchedules) {
+		switch {
+		case pair.old == nil:
+			diff.AddedFlights = append(diff.AddedFlights, pair.new)
+		case pair.new == nil:
+			diff.RemovedFlights = append(diff.RemovedFlights, pair.old)
+		default:
+			for _, status := range seatStatuses {
+				before, after := oldGaps[pair.old][status], newGaps[pair.new][status]
+				if before != after {
+					diff.Gaps = append(diff.Gaps, &GapChange{Flight: pair.new, Status: status, Before: before, After: after})
+				}
+			}
+		}
+	}
+
+	return diff
+}
+
+// The same flight in two schedules; either side is nil when the flight is only in the other
+type flightPair struct {
+	old *Flight
+	new *Flight
+}
+
+// Pairs flights by date, time and type, in order, so the second of two identical flights pairs with the second.
+// Pairs come out in the order of the new schedule, with flights only in the old one after their day's flights
+func matchFlights(oldSchedules *FlightSchedules, newSchedules *FlightSchedules) []*flightPair {
+	var (
+		pairs     = []*flightPair{}
+		unmatched = make(map[string][]*Flight) // Key: date, time and type; Value: old flights not yet paired
+		paired    = make(map[*Flight]bool)
+	)
+
+	for _, flight := range oldSchedules.Flights {
+		key := flightKey(flight)
+		unmatched[key] = append(unmatched[key], flight)
+	}
+
+	for _, day := range flightIndexesByDate(newSchedules) {
+		for _, flightIndex := range day {
+			flight := newSchedules.Flights[flightIndex]
+			pair := &flightPair{new: flight}
+
+			if candidates := unmatched[flightKey(flight)]; len(candidates) > 0 {
+				pair.old = candidates[0]
+				paired[pair.old] = true
+				unmatched[flightKey(flight)] = candidates[1:]
+			}
+
+			pairs = append(pairs, pair)
+		}
+
+		for _, flight := range oldSchedules.Flights {
+			if flight.Date == newSchedules.Flights[day[0]].Date && !paired[flight] {
+				pairs = append(pairs, &flightPair{old: flight})
+				paired[flight] = true
+			}
+		}
+	}
+
+	for _, flight := range oldSchedules.Flights { // Days the new schedule doesn't have at all
+		if !paired[flight] {
+			pairs = append(pairs, &flightPair{old: flight})
+		}
+	}
+
+	return pairs
+}
+
+func flightKey(flight *Flight) string {
+	return fmt.Sprintf("%s %s %s", flight.Date, flight.Time, flight.Type)
+}
+
+// Every seat that differs between the schedules, flight by flight. Crew who leave one flight for another the
+// same day are moved rather than removed and added. Days in skip are left out; removed gives the reason crew lost
+// a seat
+func crewChanges(oldSchedules *FlightSchedules, newSchedules *FlightSchedules, skip map[string]bool, removed map[*CrewMember]string) []*Change {
+	var (
+		changes  = []*Change{}
+		pairs    = matchFlights(oldSchedules, newSchedules)
+		oldByDay = make(map[string]map[string][]*Flight) // Key: date; Value: old flights by crew name
+		newByDay = make(map[string]map[string][]*Flight)
+		oldOf    = make(map[*Flight]*Flight) // Key: new flight; Value: the old flight it's paired with
+		newOf    = make(map[*Flight]*Flight)
+	)
+
+	crewByDay := func(flightSchedules *FlightSchedules, byDay map[string]map[string][]*Flight) {
+		for _, flight := range flightSchedules.Flights {
+			if byDay[flight.Date] == nil {
+				byDay[flight.Date] = make(map[string][]*Flight)
+			}
+			for _, crew := range flight.crew() {
+				key := hoursKey(crew.FirstName, crew.LastName)
+				byDay[flight.Date][key] = append(byDay[flight.Date][key], flight)
+			}
+		}
+	}
+	crewByDay(oldSchedules, oldByDay)
+	crewByDay(newSchedules, newByDay)
+
+	for _, pair := range pairs {
+		if pair.old != nil && pair.new != nil {
+			oldOf[pair.new] = pair.old
+			newOf[pair.old] = pair.new
+		}
+	}
+
+	// A flight the crew member is on in one schedule but not on its counterpart in the other
+	leftFor := func(flights []*Flight, counterpart map[*Flight]*Flight, key string) *Flight {
+		for _, flight := range flights {
+			if !hasCrew(counterpart[flight], key) {
+				return flight
+			}
+		}
+		return nil
+	}
+
+	for _, pair := range pairs {
+		flight := pair.new
+		if flight == nil {
+			flight = pair.old
+		}
+		if skip[flight.Date] {
+			continue
+		}
+
+		if pair.old != nil {
+			for _, crew := range pair.old.crew() {
+				key := hoursKey(crew.FirstName, crew.LastName)
+				if hasCrew(pair.new, key) {
+					continue
+				}
+				if leftFor(newByDay[flight.Date][key], oldOf, key) == nil {
+					changes = append(changes, &Change{Flight: flight, Crew: crew, Kind: CHANGE_REMOVED, Details: removed[crew]})
+				}
+			}
+		}
+
+		if pair.new == nil {
+			continue
+		}
+
+		for _, crew := range pair.new.crew() {
+			key := hoursKey(crew.FirstName, crew.LastName)
+			if hasCrew(pair.old, key) {
+				continue
+			}
+			if was := leftFor(oldByDay[flight.Date][key], newOf, key); was != nil {
+				changes = append(changes, &Change{Flight: flight, Crew: crew, Kind: CHANGE_MOVED, Details: fmt.Sprintf("from the %s %s flight", was.Time, was.Type)})
+			} else {
+				changes = append(changes, &Change{Flight: flight, Crew: crew, Kind: CHANGE_ADDED})
+			}
+		}
+	}
+
+	return changes
+}
+
+// Whether the crew member with the given hours key is on the flight, which may be nil
+func hasCrew(flight *Flight, key string) bool {
+	if flight == nil {
+		return false
+	}
+	for _, crew := range flight.crew() {
+		if hoursKey(crew.FirstName, crew.LastName) == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Key: flight; Value: unfilled seats by status
+func gapsByFlight(flightSchedules *FlightSchedules) map[*Flight]map[string]int {
+	gaps := make(map[*Flight]map[string]int)
+
+	for _, seat := range flightSchedules.Unfilled {
+		flight := flightSchedules.Flights[seat.FlightIndex]
+		if gaps[flight] == nil {
+			gaps[flight] = make(map[string]int)
+		}
+		gaps[flight][seat.Status]++
+	}
+
+	return gaps
+}
+
+// Crew whose number of flights or hours differs between the schedules, by status and then name
+func loadChanges(oldSchedules *FlightSchedules, newSchedules *FlightSchedules) []*LoadChange {
+	var (
+		loads  = []*LoadChange{}
+		byName = make(map[string]*LoadChange)
+	)
+
+	count := func(flightSchedules *FlightSchedules, old bool) {
+		for _, flight := range flightSchedules.Flights {
+			for _, crew := range flight.crew() {
+				key := hoursKey(crew.FirstName, crew.LastName)
+				load, ok := byName[key]
+				if !ok {
+					load = &LoadChange{Crew: crew}
+					byName[key] = load
+					loads = append(loads, load)
+				}
+
+				if old {
+					load.Before++
+					load.HoursBefore += flight.Duration.Hours()
+				} else {
+					load.After++
+					load.HoursAfter += flight.Duration.Hours()
+				}
+			}
+		}
+	}
+	count(oldSchedules, true)
+	count(newSchedules, false)
+
+	changed := []*LoadChange{}
+	for _, load := range loads {
+		if load.Before != load.After || load.HoursBefore != load.HoursAfter {
+			changed = append(changed, load)
+		}
+	}
+
+	sort.SliceStable(changed, func(a, b int) bool {
+		if changed[a].Crew.Status != changed[b].Crew.Status {
+			return statusOrder(changed[a].Crew.Status) < statusOrder(changed[b].Crew.Status)
+		}
+		return crewName(changed[a].Crew.LastName, changed[a].Crew.FirstName) < crewName(changed[b].Crew.LastName, changed[b].Crew.FirstName)
+	})
+
+	return changed
+}
+
+func statusOrder(status string) int {
+	for i, s := range seatStatuses {
+		if s == status {
+			return i
+		}
+	}
+	return len(seatStatuses)
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const diffDate = "Jan 05 26"
+
+// Flights on diffDate, each written as its time and type followed by the first names of its PIs, e.g. "0800
+// NORMAL PI0 PI1"
+func testDiffSchedule(flights ...string) *FlightSchedules {
+	flightSchedules := &FlightSchedules{Flights: []*Flight{}, Unfilled: []*UnfilledSeat{}}
+	for _, description := range flights {
+		fields := strings.Fields(description)
+		flight := &Flight{Time: fields[0], Type: fields[1], Date: diffDate, Duration: 2 * time.Hour}
+		for _, firstName := range fields[2:] {
+			seatCrew(flight, &CrewMember{FirstName: firstName, LastName: "Doe", Rank: "CW2", Status: "PI"})
+		}
+		flightSchedules.Flights = append(flightSchedules.Flights, flight)
+	}
+	return flightSchedules
+}
+
+func TestDiffFlightSchedules(t *testing.T) {
+	tests := []struct {
+		name        string
+		old         []string
+		new         []string
+		wantAdded   []string // Time of each flight only in the new schedule
+		wantRemoved []string
+		wantChanges []string
+		wantLoads   []string
+	}{
+		{
+			name: "same crew",
+			old:  []string{"0800 NORMAL PI0", "1200 NORMAL PI1"},
+			new:  []string{"0800 NORMAL PI0", "1200 NORMAL PI1"},
+		},
+		{
+			name:        "crew added",
+			old:         []string{"0800 NORMAL PI0"},
+			new:         []string{"0800 NORMAL PI0 PI1"},
+			wantChanges: []string{"Jan 05 26 0800 NORMAL: added PI CW2 PI1 Doe"},
+			wantLoads:   []string{"PI CW2 PI1 Doe: 0 -> 1 flights, 0.0 -> 2.0 hours"},
+		},
+		{
+			name:        "crew removed",
+			old:         []string{"0800 NORMAL PI0 PI1"},
+			new:         []string{"0800 NORMAL PI0"},
+			wantChanges: []string{"Jan 05 26 0800 NORMAL: removed PI CW2 PI1 Doe"},
+			wantLoads:   []string{"PI CW2 PI1 Doe: 1 -> 0 flights, 2.0 -> 0.0 hours"},
+		},
+		{
+			name:        "crew moved to another flight that day",
+			old:         []string{"0800 NORMAL PI0", "1200 NORMAL"},
+			new:         []string{"0800 NORMAL", "1200 NORMAL PI0"},
+			wantChanges: []string{"Jan 05 26 1200 NORMAL: moved PI CW2 PI0 Doe (from the 0800 NORMAL flight)"},
+		},
+		{
+			name:        "crew swapped between flights",
+			old:         []string{"0800 NORMAL PI0", "1200 NORMAL PI1"},
+			new:         []string{"0800 NORMAL PI1", "1200 NORMAL PI0"},
+			wantChanges: []string{"Jan 05 26 0800 NORMAL: moved PI CW2 PI1 Doe (from the 1200 NORMAL flight)", "Jan 05 26 1200 NORMAL: moved PI CW2 PI0 Doe (from the 0800 NORMAL flight)"},
+		},
+		{
+			name:        "flight added",
+			old:         []string{"0800 NORMAL PI0"},
+			new:         []string{"0800 NORMAL PI0", "1700 NORMAL PI1"},
+			wantAdded:   []string{"1700"},
+			wantChanges: []string{"Jan 05 26 1700 NORMAL: added PI CW2 PI1 Doe"},
+			wantLoads:   []string{"PI CW2 PI1 Doe: 0 -> 1 flights, 0.0 -> 2.0 hours"},
+		},
+		{
+			name:        "flight removed",
+			old:         []string{"0800 NORMAL PI0", "1700 NORMAL PI1"},
+			new:         []string{"0800 NORMAL PI0"},
+			wantRemoved: []string{"1700"},
+			wantChanges: []string{"Jan 05 26 1700 NORMAL: removed PI CW2 PI1 Doe"},
+			wantLoads:   []string{"PI CW2 PI1 Doe: 1 -> 0 flights, 2.0 -> 0.0 hours"},
+		},
+		{
+			name: "identical flights paired in order",
+			old:  []string{"1200 NORMAL PI0", "1200 NORMAL PI1"},
+			new:  []string{"1200 NORMAL PI0", "1200 NORMAL PI1"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			diff := diffFlightSchedules(testDiffSchedule(test.old...), testDiffSchedule(test.new...))
+
+			times := func(flights []*Flight) []string {
+				got := []string{}
+				for _, flight := range flights {
+					got = append(got, flight.Time)
+				}
+				return got
+			}
+			changes := []string{}
+			for _, change := range diff.Changes {
+				changes = append(changes, change.String())
+			}
+			loads := []string{}
+			for _, load := range diff.Loads {
+				loads = append(loads, load.String())
+			}
+
+			for _, check := range []struct {
+				name      string
+				got, want []string
+			}{
+				{"AddedFlights", times(diff.AddedFlights), test.wantAdded},
+				{"RemovedFlights", times(diff.RemovedFlights), test.wantRemoved},
+				{"Changes", changes, test.wantChanges},
+				{"Loads", loads, test.wantLoads},
+			} {
+				if check.want == nil {
+					check.want = []string{}
+				}
+				if !reflect.DeepEqual(check.got, check.want) {
+					t.Errorf("%s = %q, want %q", check.name, check.got, check.want)
+				}
+			}
+
+			if want := len(test.wantAdded)+len(test.wantRemoved)+len(test.wantChanges)+len(test.wantLoads) == 0; diff.empty() != want {
+				t.Errorf("empty = %v, want %v", diff.empty(), want)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const DEFAULT_POLICY = "default"
+
+/*
+How much crew can fly in a single day, under "duty_day" in the flight config and keyed by crew status, a crew
+member's name or "default", e.g.
+
+	"duty_day": {
+		"default": {"max_flights": 1},
+		"CE": {"max_flights": 2, "max_hours": 5, "combinations": [["TRAINING", "NORMAL"]]},
+		"Doe, Jane": {"max_flights": 1}
+	}
+
+lets a CE fly a morning sim and an afternoon normal flight, as long as the two come to 5 hours or less.
+A name takes precedence over a status, and a status over "default". "max_flights" is 1 if it isn't given and
+"max_hours" of 0 is no limit; otherwise it caps a day's only flight too. When "combinations" is given, a day
+with more than one flight has to be made up of flight types from one of them, each used at most as often as
+it's listed. Flights the same person is on can never overlap. Without "duty_day", everyone flies at most one
+flight a day.
+*/
+type DutyDayPolicy struct {
+	MaxFlights   int        `json:"max_flights"`
+	MaxHours     float64    `json:"max_hours"`
+	Combinations [][]string `json:"combinations,omitempty"`
+}
+
+func defaultDutyDayPolicies() map[string]*DutyDayPolicy {
+	return map[string]*DutyDayPolicy{
+		DEFAULT_POLICY: {MaxFlights: 1},
+	}
+}
+
+func validateDutyDayPolicies(policies map[string]*DutyDayPolicy) error {
+	if _, ok := policies[DEFAULT_POLICY]; !ok {
+		policies[DEFAULT_POLICY] = &DutyDayPolicy{MaxFlights: 1}
+	}
+
+	for key, policy := range policies {
+		if policy == nil {
+			return fmt.Errorf("duty_day.%s is empty", key)
+		}
+
+		// Names are matched the way info.xlsx matches them; statuses and "default" are kept as they are
+		if parts := strings.SplitN(key, ",", 2); len(parts) == 2 {
+			name := hoursKey(parts[1], parts[0])
+			if name != key {
+				if _, ok := policies[name]; ok {
+					return fmt.Errorf("duty_day.%s is listed twice", key)
+				}
+				delete(policies, key)
+				policies[name] = policy
+			}
+		} else if key != DEFAULT_POLICY && !isCrewStatus(key) {
+			return fmt.Errorf("duty_day.%s is not a crew status, a name like \"Doe, Jane\" or %q", key, DEFAULT_POLICY)
+		}
+
+		if policy.MaxFlights == 0 {
+			policy.MaxFlights = 1
+		}
+		if policy.MaxFlights < 0 {
+			return fmt.Errorf("duty_day.%s.max_flights is negative", key)
+		}
+		if policy.MaxHours < 0 {
+			return fmt.Errorf("duty_day.%s.max_hours is negative", key)
+		}
+
+		for i, combination := range policy.Combinations {
+			if len(combination) < 2 {
+				return fmt.Errorf("duty_day.%s.combinations[%d] needs at least two flight types", key, i)
+			}
+			for _, flightType := range combination {
+				if !isFlightType(flightType) {
+					return fmt.Errorf("duty_day.%s.combinations[%d] has unknown flight type %q", key, i, flightType)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// Policy for a crew member: theirs if they have one, otherwise their status's, otherwise the default
+func (c *FlightConfig) dutyDayPolicy(firstName string, lastName string, status string) *DutyDayPolicy {
+	if policy, ok := c.DutyDay[hoursKey(firstName, lastName)]; ok {
+		return policy
+	}
+	if policy, ok := c.DutyDay[status]; ok {
+		return policy
+	}
+	return c.DutyDay[DEFAULT_POLICY]
+}
+
+// Why crew already on the given flights that day can't also fly the flight, or blank if they can
+func (p *DutyDayPolicy) violation(flights []*Flight, flight *Flight) string {
+	if p.tooLong(flight.Duration) {
+		return fmt.Sprintf("would fly %gh that day; at most %gh", flight.Duration.Hours(), p.MaxHours)
+	}
+	if len(flights) == 0 {
+		return ""
+	}
+
+	for _, other := range flights {
+		if other == flight {
+			return "already on the flight"
+		}
+	}
+
+	if len(flights) >= p.MaxFlights {
+		if p.MaxFlights == 1 {
+			return fmt.Sprintf("already on the %s %s flight that day", flights[0].Time, flights[0].Type)
+		}
+		return fmt.Sprintf("already on %d flights that day; at most %d", len(flights), p.MaxFlights)
+	}
+
+	start, err := flightStart(flight)
+	if err != nil {
+		return ""
+	}
+	end := start.Add(flight.Duration)
+	hours := flight.Duration
+
+	for _, other := range flights {
+		otherStart, err := flightStart(other)
+		if err != nil {
+			continue
+		}
+		if start.Before(otherStart.Add(other.Duration)) && otherStart.Before(end) {
+			return fmt.Sprintf("on the %s %s flight at the same time", other.Time, other.Type)
+		}
+		hours += other.Duration
+	}
+
+	if p.tooLong(hours) {
+		return fmt.Sprintf("would fly %gh that day; at most %gh", hours.Hours(), p.MaxHours)
+	}
+
+	if len(p.Combinations) > 0 && !p.allowsTypes(append([]*Flight{flight}, flights...)) {
+		types := []string{}
+		for _, other := range flights {
+			types = append(types, other.Type)
+		}
+		return fmt.Sprintf("can't fly a %s flight on the same day as %s", flight.Type, strings.Join(types, " and "))
+	}
+
+	return ""
+}
+
+// Whether the hours are more than the policy allows in a day
+func (p *DutyDayPolicy) tooLong(hours time.Duration) bool {
+	return p.MaxHours > 0 && hours > time.Duration(p.MaxHours*float64(time.Hour))
+}
+
+// Whether the flights' types fit within one of the combinations
+func (p *DutyDayPolicy) allowsTypes(flights []*Flight) bool {
+	for _, combination := range p.Combinations {
+		left := make(map[string]int)
+		for _, flightType := range combination {
+			left[flightType]++
+		}
+
+		fits := true
+		for _, flight := range flights {
+			left[flight.Type]--
+			fits = fits && left[flight.Type] >= 0
+		}
+		if fits {
+			return true
+		}
+	}
+	return false
+}
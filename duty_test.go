@@ -0,0 +1,108 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+const dutyDate = "Jan 05 26"
+
+func TestDutyDayViolation(t *testing.T) {
+	testFlight := func(flightType string, flightTime string, hours float64) *Flight {
+		return &Flight{Type: flightType, Date: dutyDate, Time: flightTime, Duration: time.Duration(hours * float64(time.Hour))}
+	}
+	sim := testFlight("TRAINING", "0800", 1)
+
+	tests := []struct {
+		name    string
+		policy  *DutyDayPolicy
+		flights []*Flight // Already on that day
+		flight  *Flight
+		want    string
+	}{
+		{
+			name:   "first flight of the day",
+			policy: &DutyDayPolicy{MaxFlights: 1},
+			flight: testFlight("NORMAL", "1200", 3),
+		},
+		{
+			name:   "single flight over max_hours",
+			policy: &DutyDayPolicy{MaxFlights: 1, MaxHours: 2},
+			flight: testFlight("NORMAL", "1200", 3),
+			want:   "would fly 3h that day; at most 2h",
+		},
+		{
+			name:   "single flight at max_hours",
+			policy: &DutyDayPolicy{MaxFlights: 1, MaxHours: 3},
+			flight: testFlight("NORMAL", "1200", 3),
+		},
+		{
+			name:    "second flight past max_flights of 1",
+			policy:  &DutyDayPolicy{MaxFlights: 1},
+			flights: []*Flight{sim},
+			flight:  testFlight("NORMAL", "1200", 3),
+			want:    "already on the 0800 TRAINING flight that day",
+		},
+		{
+			name:    "third flight past max_flights of 2",
+			policy:  &DutyDayPolicy{MaxFlights: 2},
+			flights: []*Flight{sim, testFlight("NORMAL", "1200", 3)},
+			flight:  testFlight("NORMAL", "1700", 3),
+			want:    "already on 2 flights that day; at most 2",
+		},
+		{
+			name:    "two flights within max_hours",
+			policy:  &DutyDayPolicy{MaxFlights: 2, MaxHours: 4},
+			flights: []*Flight{sim},
+			flight:  testFlight("NORMAL", "1200", 3),
+		},
+		{
+			name:    "two flights over max_hours combined",
+			policy:  &DutyDayPolicy{MaxFlights: 2, MaxHours: 3.5},
+			flights: []*Flight{sim},
+			flight:  testFlight("NORMAL", "1200", 3),
+			want:    "would fly 4h that day; at most 3.5h",
+		},
+		{
+			name:    "overlapping flights",
+			policy:  &DutyDayPolicy{MaxFlights: 2},
+			flights: []*Flight{testFlight("TRAINING", "0800", 2)},
+			flight:  testFlight("NORMAL", "0900", 3),
+			want:    "on the 0800 TRAINING flight at the same time",
+		},
+		{
+			name:    "back to back flights",
+			policy:  &DutyDayPolicy{MaxFlights: 2},
+			flights: []*Flight{testFlight("TRAINING", "0800", 1)},
+			flight:  testFlight("NORMAL", "0900", 3),
+		},
+		{
+			name:    "same flight twice",
+			policy:  &DutyDayPolicy{MaxFlights: 2},
+			flights: []*Flight{sim},
+			flight:  sim,
+			want:    "already on the flight",
+		},
+		{
+			name:    "allowed combination",
+			policy:  &DutyDayPolicy{MaxFlights: 2, Combinations: [][]string{{"TRAINING", "NORMAL"}}},
+			flights: []*Flight{sim},
+			flight:  testFlight("NORMAL", "1200", 3),
+		},
+		{
+			name:    "type used more often than its combination lists it",
+			policy:  &DutyDayPolicy{MaxFlights: 2, Combinations: [][]string{{"TRAINING", "NORMAL"}}},
+			flights: []*Flight{testFlight("NORMAL", "1200", 3)},
+			flight:  testFlight("NORMAL", "1700", 3),
+			want:    "can't fly a NORMAL flight on the same day as NORMAL",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.policy.violation(test.flights, test.flight); got != test.want {
+				t.Errorf("violation = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/tealeg/xlsx"
+)
+
+// Input workbook doesn't exist
+type FileNotFoundError struct {
+	File string
+}
+
+func (e *FileNotFoundError) Error() string {
+	return fmt.Sprintf("%s not found", e.File)
+}
+
+// Workbook doesn't have the sheet we read from
+type SheetMissingError struct {
+	File  string
+	Sheet string // Empty when the workbook has no sheets at all
+}
+
+func (e *SheetMissingError) Error() string {
+	if e.Sheet == "" {
+		return fmt.Sprintf("%s has no sheets", e.File)
+	}
+	return fmt.Sprintf("%s has no sheet named %q", e.File, e.Sheet)
+}
+
+// Header rows of a sheet can't be made sense of
+type HeaderError struct {
+	File    string
+	Sheet   string
+	Row     int // Zero based; -1 when the problem isn't with a single cell
+	Col     int
+	Problem string
+}
+
+func (e *HeaderError) Error() string {
+	if e.Row < 0 {
+		return fmt.Sprintf("%s, sheet %q, header: %s", e.File, e.Sheet, e.Problem)
+	}
+	return fmt.Sprintf("%s, sheet %q, header cell %s: %s", e.File, e.Sheet, cellName(e.Row, e.Col), e.Problem)
+}
+
+// A cell outside the header holds something we can't use
+type CellError struct {
+	File    string
+	Sheet   string
+	Row     int // Zero based
+	Col     int
+	Problem string
+}
+
+func (e *CellError) Error() string {
+	return fmt.Sprintf("%s, sheet %q, cell %s: %s", e.File, e.Sheet, cellName(e.Row, e.Col), e.Problem)
+}
+
+// Crew pinned to a flight they can't be put on
+type PinError struct {
+	Pin     *Pin
+	Problem string
+}
+
+func (e *PinError) Error() string {
+	return fmt.Sprintf("%s: pin of %s: %s", e.Pin.Source, e.Pin, e.Problem)
+}
+
+// A JSON document doesn't match its schema
+type JSONError struct {
+	File    string
+	Path    string // e.g. crew[2].status; empty when the problem is with the whole document
+	Problem string
+}
+
+func (e *JSONError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("%s: %s", e.File, e.Problem)
+	}
+	return fmt.Sprintf("%s, %s: %s", e.File, e.Path, e.Problem)
+}
+
+// Every problem found with the input, so they can all be fixed in one go
+type ValidationError struct {
+	Problems []error
+}
+
+func (e *ValidationError) Error() string {
+	lines := []string{}
+	for _, problem := range e.Problems {
+		lines = append(lines, "- "+problem.Error())
+	}
+
+	if len(e.Problems) == 1 {
+		return fmt.Sprintf("Found a problem with the input:\n%s", strings.Join(lines, "\n"))
+	}
+	return fmt.Sprintf("Found %d problems with the input:\n%s", len(e.Problems), strings.Join(lines, "\n"))
+}
+
+// True when err is down to the input workbooks rather than something going wrong on our end
+func isInputError(err error) bool {
+	var (
+		fileNotFound *FileNotFoundError
+		sheetMissing *SheetMissingError
+		header       *HeaderError
+		cell         *CellError
+		validation   *ValidationError
+		pin          *PinError
+		jsonError    *JSONError
+	)
+
+	return errors.As(err, &fileNotFound) || errors.As(err, &sheetMissing) || errors.As(err, &header) ||
+		errors.As(err, &cell) || errors.As(err, &validation) || errors.As(err, &pin) ||
+		errors.As(err, &jsonError)
+}
+
+func openWorkbook(fileName string) (*xlsx.File, error) {
+	if _, err := os.Stat(fileName); os.IsNotExist(err) {
+		return nil, &FileNotFoundError{File: fileName}
+	}
+
+	file, err := xlsx.OpenFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("Error opening %s: %s", fileName, err)
+	}
+
+	return file, nil
+}
+
+// Formatted value of a cell, or blank if the row is too short to have it
+func cellValue(row *xlsx.Row, col int) (string, error) {
+	if col >= len(row.Cells) {
+		return "", nil
+	}
+	return row.Cells[col].FormattedValue()
+}
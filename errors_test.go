@@ -0,0 +1,104 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/tealeg/xlsx"
+)
+
+func TestCellName(t *testing.T) {
+	tests := []struct {
+		row, col int
+		want     string
+	}{
+		{row: 0, col: 0, want: "A1"},
+		{row: 1, col: 25, want: "Z2"},
+		{row: 9, col: 26, want: "AA10"},
+		{row: 0, col: 51, want: "AZ1"},
+		{row: 0, col: 52, want: "BA1"},
+		{row: 0, col: 701, want: "ZZ1"},
+		{row: 0, col: 702, want: "AAA1"},
+	}
+
+	for _, test := range tests {
+		if got := cellName(test.row, test.col); got != test.want {
+			t.Errorf("cellName(%d, %d) = %q, want %q", test.row, test.col, got, test.want)
+		}
+	}
+}
+
+func TestInputErrorMessages(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{err: &FileNotFoundError{File: "Troop to Task.xlsx"}, want: "Troop to Task.xlsx not found"},
+		{err: &SheetMissingError{File: "info.xlsx"}, want: "info.xlsx has no sheets"},
+		{err: &SheetMissingError{File: "info.xlsx", Sheet: "Hours"}, want: `info.xlsx has no sheet named "Hours"`},
+		{err: &HeaderError{File: "ttt.xlsx", Sheet: "Schedule", Row: -1, Problem: "no dates found"}, want: `ttt.xlsx, sheet "Schedule", header: no dates found`},
+		{err: &HeaderError{File: "ttt.xlsx", Sheet: "Schedule", Row: 1, Col: 3, Problem: "bad day"}, want: `ttt.xlsx, sheet "Schedule", header cell D2: bad day`},
+		{err: &CellError{File: "info.xlsx", Sheet: "Hours", Row: 4, Col: 2, Problem: "not a number"}, want: `info.xlsx, sheet "Hours", cell C5: not a number`},
+		{
+			err:  &ValidationError{Problems: []error{&FileNotFoundError{File: "a.xlsx"}}},
+			want: "Found a problem with the input:\n- a.xlsx not found",
+		},
+		{
+			err:  &ValidationError{Problems: []error{&FileNotFoundError{File: "a.xlsx"}, &FileNotFoundError{File: "b.xlsx"}}},
+			want: "Found 2 problems with the input:\n- a.xlsx not found\n- b.xlsx not found",
+		},
+	}
+
+	for _, test := range tests {
+		if got := test.err.Error(); got != test.want {
+			t.Errorf("Error() = %q, want %q", got, test.want)
+		}
+	}
+}
+
+// Every bad row of the hours sheet is reported, rather than only the first
+func TestCrewPayloadFromXLSXCollectsProblems(t *testing.T) {
+	file := xlsx.NewFile()
+	sheet, err := file.AddSheet("Hours")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, values := range [][]string{
+		{"Name", "Rank", "Hours"},
+		{"Doe, Jane", "CW3", "12.5"},
+		{"Roe, John", "CW2", "lots"},
+		{"Poe, Kim", "SGT"},
+	} {
+		row := sheet.AddRow()
+		for _, value := range values {
+			row.AddCell().Value = value
+		}
+	}
+
+	crewPayload, err := crewPayloadFromXLSX(file, "info.xlsx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(crewPayload.CrewMembers) != 1 || crewPayload.CrewMembers[0].Hours != 12.5 {
+		t.Errorf("CrewMembers = %+v, want only Jane Doe with 12.5 hours", crewPayload.CrewMembers)
+	}
+
+	err = checkPayloadsForFunnyBusiness(NewSchedulePayload(nil), "ttt.xlsx", crewPayload, "info.xlsx", nil)
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("err = %v, want a ValidationError", err)
+	}
+
+	cells := []string{}
+	for _, problem := range validationErr.Problems {
+		var cellErr *CellError
+		if errors.As(problem, &cellErr) && cellErr.File == "info.xlsx" {
+			cells = append(cells, cellName(cellErr.Row, cellErr.Col)+": "+cellErr.Problem)
+		}
+	}
+	want := []string{"C3: hours for Roe, John aren't a number", "C4: no hours for Poe, Kim"}
+	if !reflect.DeepEqual(cells, want) {
+		t.Errorf("problems = %q, want %q", cells, want)
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const (
+	HISTORY_FILE           = "files/history.json"
+	HISTORY_DATE_FORMAT    = "2006-01-02"
+	DEFAULT_HISTORY_WEEKS  = 6
+	HISTORY_FLIGHT_PENALTY = 3 // Cost per flight a crew member flew in the history window
+)
+
+// Every flight assignment from previous runs, so fairness can span a whole rotation rather than one run
+type History struct {
+	Assignments []*HistoryAssignment `json:"assignments"`
+}
+
+type HistoryAssignment struct {
+	Date      string `json:"date"` // HISTORY_DATE_FORMAT
+	Time      string `json:"time"`
+	Type      string `json:"type"`
+	Status    string `json:"status"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Key       string `json:"key"` // Hours key of the crew member, so spacing and case in the names don't matter
+}
+
+// Reads the history file; a missing file is an empty history. Assignments written before they had a key get one
+func loadHistory(fileName string) (*History, error) {
+	history := &History{Assignments: []*HistoryAssignment{}}
+
+	data, err := os.ReadFile(fileName)
+	if os.IsNotExist(err) {
+		return history, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, history); err != nil {
+		return nil, fmt.Errorf("Error parsing %s: %s", fileName, err)
+	}
+
+	for _, assignment := range history.Assignments {
+		if assignment.Key == "" {
+			assignment.Key = hoursKey(assignment.FirstName, assignment.LastName)
+		}
+	}
+
+	return history, nil
+}
+
+func (h *History) save(fileName string) error {
+	data, err := json.MarshalIndent(h, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(fileName), 0700); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so an interrupted save can't lose the existing history
+	tmpFileName := fileName + ".tmp"
+	if err := os.WriteFile(tmpFileName, data, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpFileName, fileName)
+}
+
+// Key: hours key; Value: flights flown in the given number of weeks before start
+func (h *History) recentFlights(start time.Time, weeks int) map[string]int {
+	var (
+		counts      = make(map[string]int)
+		windowStart = calendarDay(start).AddDate(0, 0, -7*weeks)
+		windowEnd   = calendarDay(start)
+	)
+
+	for _, assignment := range h.Assignments {
+		date, err := time.Parse(HISTORY_DATE_FORMAT, assignment.Date)
+		if err != nil || date.Before(windowStart) || !date.Before(windowEnd) {
+			continue
+		}
+
+		counts[assignment.Key]++
+	}
+
+	return counts
+}
+
+// Replaces whatever was recorded for the scheduled dates with the new assignments
+func (h *History) record(flightSchedules *FlightSchedules) error {
+	var (
+		scheduled   = make(map[string]bool)
+		assignments = []*HistoryAssignment{}
+	)
+
+	for _, flight := range flightSchedules.Flights {
+		date, err := time.Parse(FULL_DATE_FORMAT, flight.Date)
+		if err != nil {
+			return err
+		}
+		scheduled[date.Format(HISTORY_DATE_FORMAT)] = true
+
+		for _, crew := range flight.crew() {
+			assignments = append(assignments, &HistoryAssignment{
+				Date:      date.Format(HISTORY_DATE_FORMAT),
+				Time:      flight.Time,
+				Type:      flight.Type,
+				Status:    crew.Status,
+				FirstName: crew.FirstName,
+				LastName:  crew.LastName,
+				Key:       hoursKey(crew.FirstName, crew.LastName),
+			})
+		}
+	}
+
+	for _, assignment := range h.Assignments {
+		if !scheduled[assignment.Date] {
+			assignments = append(assignments, assignment)
+		}
+	}
+
+	sort.SliceStable(assignments, func(a, b int) bool {
+		if assignments[a].Date == assignments[b].Date {
+			return assignments[a].Time < assignments[b].Time
+		}
+		return assignments[a].Date < assignments[b].Date
+	})
+
+	h.Assignments = assignments
+
+	return nil
+}
+
+// First day being planned
+func planningStart() (time.Time, error) {
+	var start time.Time
+
+	for _, date := range dates {
+		inputDate, err := time.Parse(INPUT_DATE_FORMAT, date)
+		if err != nil {
+			return start, err
+		}
+
+		if start.IsZero() || inputDate.Before(start) {
+			start = inputDate
+		}
+	}
+
+	return start, nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func historyFlight(date string, clock string, crew ...*CrewMember) *Flight {
+	return &Flight{Type: "NORMAL", Date: date, Time: clock, PIs: crew}
+}
+
+func TestHistoryRecordReplacesScheduledDates(t *testing.T) {
+	var (
+		able  = &CrewMember{FirstName: "Pat", LastName: "Able", Status: "PI"}
+		baker = &CrewMember{FirstName: "Pat", LastName: "Baker", Status: "PI"}
+		cole  = &CrewMember{FirstName: "Pat", LastName: "Cole", Status: "PI"}
+	)
+
+	history := &History{Assignments: []*HistoryAssignment{}}
+	first := &FlightSchedules{Flights: []*Flight{
+		historyFlight("Mar 02 26", "0900", able),
+		historyFlight("Mar 03 26", "0900", baker),
+	}}
+	if err := history.record(first); err != nil {
+		t.Fatal(err)
+	}
+
+	// Planning Mar 03 again replaces Baker with Cole but leaves Mar 02 alone
+	again := &FlightSchedules{Flights: []*Flight{historyFlight("Mar 03 26", "1300", cole)}}
+	if err := history.record(again); err != nil {
+		t.Fatal(err)
+	}
+
+	got := []string{}
+	for _, assignment := range history.Assignments {
+		got = append(got, assignment.Date+" "+assignment.Time+" "+assignment.Key)
+	}
+	want := []string{
+		"2026-03-02 0900 " + hoursKey("Pat", "Able"),
+		"2026-03-03 1300 " + hoursKey("Pat", "Cole"),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("assignments = %q, want %q", got, want)
+	}
+
+	// And the history survives a save and load
+	fileName := filepath.Join(t.TempDir(), "history.json")
+	if err := history.save(fileName); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := loadHistory(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded, history) {
+		t.Errorf("loaded %+v, want %+v", loaded.Assignments, history.Assignments)
+	}
+}
+
+func TestHistoryRecentFlightsOnlyCountsTheWindow(t *testing.T) {
+	history := &History{Assignments: []*HistoryAssignment{
+		{Date: "2026-01-18", Key: "old"},    // 6 weeks and a day before
+		{Date: "2026-01-19", Key: "first"},  // First day of the window
+		{Date: "2026-02-28", Key: "recent"}, // Day before the start
+		{Date: "2026-02-28", Key: "recent"},
+		{Date: "2026-03-02", Key: "planned"}, // Being planned, so not history
+		{Date: "not a date", Key: "broken"},
+	}}
+
+	start := time.Date(2026, time.March, 2, 14, 0, 0, 0, time.UTC)
+	got := history.recentFlights(start, DEFAULT_HISTORY_WEEKS)
+	want := map[string]int{"first": 1, "recent": 2}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("recentFlights = %v, want %v", got, want)
+	}
+}
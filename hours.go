@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const HOURS_WEIGHT = 2 // Cost per crew member of the same status with fewer hours
+
+// Copies hours and qualifications from info.xlsx onto the roster read from scheduleFileName, matching on
+// "Last, First". Returns a warning for every name found in only one of the two files
+func joinCrewHours(schedulePayload *SchedulePayload, scheduleFileName string, crewPayload *CrewPayload, crewFileName string) []string {
+	var (
+		warnings   = []string{}
+		crewByName = make(map[string]*CrewMember)
+		matched    = make(map[string]bool)
+	)
+
+	for _, crew := range crewPayload.CrewMembers {
+		key := hoursKey(crew.FirstName, crew.LastName)
+		if _, ok := crewByName[key]; ok {
+			warnings = append(warnings, fmt.Sprintf("%s, %s is listed more than once in %s; using the first entry", crew.LastName, crew.FirstName, crewFileName))
+			continue
+		}
+		crewByName[key] = crew
+	}
+
+	for _, crew := range schedulePayload.CrewAvailability {
+		key := hoursKey(crew.FirstName, crew.LastName)
+		for qualification, expires := range crewPayload.Qualifications[key] {
+			if crew.Qualifications == nil {
+				crew.Qualifications = make(map[string]time.Time)
+			}
+			crew.Qualifications[qualification] = expires
+		}
+
+		member, ok := crewByName[key]
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("%s, %s is in %s but not %s; scheduling them as if they had average hours", crew.LastName, crew.FirstName, scheduleFileName, crewFileName))
+			continue
+		}
+
+		crew.Hours = member.Hours
+		crew.HasHours = true
+		matched[key] = true
+	}
+
+	for _, crew := range crewPayload.CrewMembers {
+		if !matched[hoursKey(crew.FirstName, crew.LastName)] {
+			warnings = append(warnings, fmt.Sprintf("%s, %s is in %s but not %s", crew.LastName, crew.FirstName, crewFileName, scheduleFileName))
+		}
+	}
+
+	return warnings
+}
+
+func hoursKey(firstName string, lastName string) string {
+	return strings.ToLower(fmt.Sprintf("%s, %s", strings.Join(strings.Fields(lastName), " "), strings.Join(strings.Fields(firstName), " ")))
+}
+
+// Hours each crew member starts the schedule with; crew missing from info.xlsx get the average of their status
+func startingHours(crew []*CrewAvailability) []float64 {
+	var (
+		hours         = make([]float64, len(crew))
+		totalHours    = make(map[string]float64)
+		knownByStatus = make(map[string]int)
+	)
+
+	for _, c := range crew {
+		if c.HasHours {
+			totalHours[c.Status] += c.Hours
+			knownByStatus[c.Status]++
+		}
+	}
+
+	for i, c := range crew {
+		if c.HasHours {
+			hours[i] = c.Hours
+		} else if knownByStatus[c.Status] > 0 {
+			hours[i] = totalHours[c.Status] / float64(knownByStatus[c.Status])
+		}
+	}
+
+	return hours
+}
+
+// Key: crew index; Value: number of crew of the same status with fewer hours
+func (solver *scheduleSolver) hoursRanks() []int {
+	ranks := make([]int, len(solver.crew))
+
+	for i, crew := range solver.crew {
+		for j, other := range solver.crew {
+			if other.Status == crew.Status && solver.hours[j] < solver.hours[i] {
+				ranks[i]++
+			}
+		}
+	}
+
+	return ranks
+}
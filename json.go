@@ -0,0 +1,686 @@
+package main
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	JSON_FORMAT_VERSION = 1
+	JSON_DATE_FORMAT    = "2006-01-02"
+
+	SCHEDULE_INPUT_KIND   = "schedule-input"   // Crew availability and the flights to crew
+	FLIGHT_SCHEDULES_KIND = "flight-schedules" // Crewed flights, as written by the scheduler
+)
+
+// JSON Schemas for both documents, published alongside the code
+//
+//go:embed schemas/*.json
+var jsonSchemas embed.FS
+
+var jsonSchemaFiles = map[string]string{
+	SCHEDULE_INPUT_KIND:   "schemas/schedule-input.v1.schema.json",
+	FLIGHT_SCHEDULES_KIND: "schemas/flight-schedules.v1.schema.json",
+}
+
+/*********Input*********/
+
+type ScheduleInputJSON struct {
+	Version int            `json:"version"`
+	Kind    string         `json:"kind"`
+	Crew    []*CrewJSON    `json:"crew"`
+	Days    []*PlanDayJSON `json:"days"`
+	Pins    []*PinJSON     `json:"pins,omitempty"`
+}
+
+type CrewJSON struct {
+	FirstName      string                       `json:"first_name"`
+	LastName       string                       `json:"last_name"`
+	Rank           string                       `json:"rank"`
+	Status         string                       `json:"status"`
+	Hours          *float64                     `json:"hours,omitempty"`
+	Availability   map[string]*AvailabilityJSON `json:"availability"` // Key: date
+	Qualifications []*QualificationJSON         `json:"qualifications,omitempty"`
+}
+
+type QualificationJSON struct {
+	Name    string `json:"name"`              // e.g. MTP
+	Expires string `json:"expires,omitempty"` // Last day it's current; blank if it doesn't lapse
+}
+
+type AvailabilityJSON struct {
+	Available bool   `json:"available"`
+	Code      string `json:"code,omitempty"`
+}
+
+type PlanDayJSON struct {
+	Date    string            `json:"date"`
+	Flights []*PlanFlightJSON `json:"flights"`
+}
+
+// A flight to crew
+type PlanFlightJSON struct {
+	Date     string              `json:"date,omitempty"` // Optional; has to be the day's date
+	Time     string              `json:"time"`
+	Type     string              `json:"type"`
+	Duration string              `json:"duration,omitempty"` // e.g. 1h30m; defaults to the template's
+	Requires map[string][]string `json:"requires,omitempty"` // Key: crew status; defaults to the template's
+}
+
+type PinJSON struct {
+	Date      string `json:"date"`
+	Time      string `json:"time"`
+	Type      string `json:"type,omitempty"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Seat      string `json:"seat,omitempty"` // Status of the seat when substituting for crew of another status
+}
+
+/*********Output*********/
+
+type FlightSchedulesJSON struct {
+	Version  int                 `json:"version"`
+	Kind     string              `json:"kind"`
+	Flights  []*FlightJSON       `json:"flights"`
+	Unfilled []*UnfilledSeatJSON `json:"unfilled"`
+	Changes  []*ChangeJSON       `json:"changes,omitempty"`
+	CutOff   []*CutOffDayJSON    `json:"cut_off,omitempty"`
+}
+
+type CutOffDayJSON struct {
+	Date        string `json:"date"`
+	Unfilled    int    `json:"unfilled"`
+	MinUnfilled int    `json:"min_unfilled"`
+}
+
+// A crewed flight
+type FlightJSON struct {
+	Date     string              `json:"date"`
+	Time     string              `json:"time"`
+	Type     string              `json:"type"`
+	Duration string              `json:"duration"`           // e.g. 1h30m0s
+	Requires map[string][]string `json:"requires,omitempty"` // Key: crew status
+	Seats    map[string]int      `json:"seats,omitempty"`
+	MaxSeats map[string]int      `json:"max_seats,omitempty"`
+	Crew     []*CrewMemberJSON   `json:"crew,omitempty"`
+}
+
+type CrewMemberJSON struct {
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Rank      string `json:"rank"`
+	Status    string `json:"status"`
+	Seat      string `json:"seat,omitempty"` // Status of the seat when substituting for crew of another status
+	Pinned    bool   `json:"pinned,omitempty"`
+}
+
+type UnfilledSeatJSON struct {
+	Flight     int              `json:"flight"` // Index into flights
+	Status     string           `json:"status"`
+	Exclusions []*ExclusionJSON `json:"exclusions"`
+}
+
+type ExclusionJSON struct {
+	Crew   *CrewMemberJSON `json:"crew,omitempty"`
+	Reason string          `json:"reason"`
+}
+
+type ChangeJSON struct {
+	Flight  *int            `json:"flight,omitempty"` // Index into flights; nil when the flight is no longer in the schedule
+	Date    string          `json:"date"`
+	Time    string          `json:"time"`
+	Type    string          `json:"type"`
+	Kind    string          `json:"kind"`
+	Crew    *CrewMemberJSON `json:"crew"`
+	Details string          `json:"details,omitempty"`
+}
+
+func isJSONFile(fileName string) bool {
+	return strings.EqualFold(filepath.Ext(fileName), ".json")
+}
+
+func jsonSchema(kind string) ([]byte, error) {
+	fileName, ok := jsonSchemaFiles[kind]
+	if !ok {
+		return nil, fmt.Errorf("no schema for %q; expected %s or %s", kind, SCHEDULE_INPUT_KIND, FLIGHT_SCHEDULES_KIND)
+	}
+	return jsonSchemas.ReadFile(fileName)
+}
+
+// Decodes a document, rejecting any version or kind other than the one expected and anything its published
+// schema doesn't allow
+func decodeJSONDocument(fileName string, kind string, document interface{}) error {
+	data, err := os.ReadFile(fileName)
+	if os.IsNotExist(err) {
+		return &FileNotFoundError{File: fileName}
+	} else if err != nil {
+		return err
+	}
+
+	var header struct {
+		Version int    `json:"version"`
+		Kind    string `json:"kind"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return &JSONError{File: fileName, Problem: err.Error()}
+	}
+	if header.Kind != kind {
+		return &JSONError{File: fileName, Path: "kind", Problem: fmt.Sprintf("is %q; expected %q", header.Kind, kind)}
+	}
+	if header.Version != JSON_FORMAT_VERSION {
+		return &JSONError{File: fileName, Path: "version", Problem: fmt.Sprintf("is %d; this scheduler reads version %d", header.Version, JSON_FORMAT_VERSION)}
+	}
+
+	problems, err := validateJSONSchema(kind, fileName, data)
+	if err != nil {
+		return err
+	}
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(document); err != nil {
+		return &JSONError{File: fileName, Problem: err.Error()}
+	}
+
+	return nil
+}
+
+func writeJSONDocument(fileName string, document interface{}) error {
+	data, err := json.MarshalIndent(document, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	if dir := filepath.Dir(fileName); dir != "." {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return err
+		}
+	}
+
+	return os.WriteFile(fileName, append(data, '\n'), 0600)
+}
+
+// Reads a JSON schedule input and, if given, joins the hours from the crew workbook
+func payloadsFromJSON(scheduleFileName string, crewFileName string) (*SchedulePayload, *FlightSchedules, []time.Time, error) {
+	log.Println("Reading", scheduleFileName)
+	schedulePayload, flightSchedules, days, err := scheduleInputFromJSON(scheduleFileName)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if crewFileName == "" {
+		return schedulePayload, flightSchedules, days, nil
+	}
+
+	log.Println("Reading", crewFileName)
+	file, err := openWorkbook(crewFileName)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	crewPayload, err := crewPayloadFromXLSX(file, crewFileName)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if len(crewPayload.problems) > 0 {
+		return nil, nil, nil, &ValidationError{Problems: crewPayload.problems}
+	}
+
+	schedulePayload.Warnings = append(schedulePayload.Warnings, joinCrewHours(schedulePayload, scheduleFileName, crewPayload, crewFileName)...)
+
+	return schedulePayload, flightSchedules, days, nil
+}
+
+// Reads crew, the days to plan and the flights to crew on them from an input document
+func scheduleInputFromJSON(fileName string) (*SchedulePayload, *FlightSchedules, []time.Time, error) {
+	input := &ScheduleInputJSON{}
+	if err := decodeJSONDocument(fileName, SCHEDULE_INPUT_KIND, input); err != nil {
+		return nil, nil, nil, err
+	}
+
+	var (
+		problems        = []error{}
+		crew            = []*CrewAvailability{}
+		flightSchedules = &FlightSchedules{Flights: []*Flight{}}
+		days            = []time.Time{}
+		crewByName      = make(map[string]string) // Key: crew name; Value: path of the crew member first listed
+		plannedDates    = make(map[string]string) // Key: date; Value: path of the day
+	)
+
+	problem := func(path string, format string, a ...interface{}) {
+		problems = append(problems, &JSONError{File: fileName, Path: path, Problem: fmt.Sprintf(format, a...)})
+	}
+
+	// The schema has already checked the document's shape, so only what it can't express is checked here
+	for i, day := range input.Days {
+		path := fmt.Sprintf("days[%d]", i)
+
+		date, err := time.Parse(JSON_DATE_FORMAT, day.Date)
+		if err != nil {
+			problem(path+".date", "%q is not a date like 2026-01-02", day.Date)
+			continue
+		}
+		if other, ok := plannedDates[day.Date]; ok {
+			problem(path+".date", "%s is already planned in %s", day.Date, other)
+			continue
+		}
+		plannedDates[day.Date] = path
+		days = append(days, date)
+
+		for j, flight := range day.Flights {
+			flightPath := fmt.Sprintf("%s.flights[%d]", path, j)
+			if flight.Date != "" && flight.Date != day.Date {
+				problem(flightPath+".date", "%s is not the day's date", flight.Date)
+			}
+
+			duration := flightConfig.flightDuration(flight.Type, flight.Time)
+			if flight.Duration != "" {
+				duration, err = time.ParseDuration(flight.Duration)
+				if err != nil || duration <= 0 {
+					problem(flightPath+".duration", "%q is not a duration like 1h30m", flight.Duration)
+					continue
+				}
+			}
+
+			requires := flightConfig.flightRequirements(flight.Type, flight.Time)
+			if flight.Requires != nil {
+				requires = make(map[string][]string)
+				for status, qualifications := range flight.Requires {
+					for _, qualification := range qualifications {
+						requires[status] = append(requires[status], qualificationName(qualification))
+					}
+				}
+			}
+
+			flightSchedules.Flights = append(flightSchedules.Flights, &Flight{
+				Type:     flight.Type,
+				Date:     date.Format(FULL_DATE_FORMAT),
+				Time:     flight.Time,
+				Duration: duration,
+				Requires: requires,
+				Rule:     flightConfig.flightCrewRule(flight.Type, flight.Time),
+			})
+		}
+	}
+
+	for i, c := range input.Crew {
+		path := fmt.Sprintf("crew[%d]", i)
+
+		key := hoursKey(c.FirstName, c.LastName)
+		if other, ok := crewByName[key]; ok {
+			problem(path, "%s, %s is already listed in %s", c.LastName, c.FirstName, other)
+			continue
+		}
+		crewByName[key] = path
+
+		availability := &CrewAvailability{
+			FirstName:   strings.TrimSpace(c.FirstName),
+			LastName:    strings.TrimSpace(c.LastName),
+			Rank:        strings.TrimSpace(c.Rank),
+			Status:      c.Status,
+			Availabilty: make(map[string]bool),
+			Codes:       make(map[string]string),
+			Row:         i,
+		}
+		if c.Hours != nil {
+			availability.Hours = *c.Hours
+			availability.HasHours = true
+		}
+
+		for day, a := range c.Availability {
+			date, err := time.Parse(JSON_DATE_FORMAT, day)
+			if err != nil {
+				problem(fmt.Sprintf("%s.availability[%q]", path, day), "is not a date like 2026-01-02")
+				continue
+			}
+
+			if codeProblem := flightConfig.availabilityCodeProblem(a.Code); codeProblem != "" {
+				problem(fmt.Sprintf("%s.availability[%q].code", path, day), "%s", codeProblem)
+			}
+
+			// A code that can't fly leaves the day unavailable whatever "available" says
+			availability.Availabilty[date.Format(FULL_DATE_FORMAT)] = a.Available && flightConfig.availabilityWindow(a.Code) != nil
+			availability.Codes[date.Format(FULL_DATE_FORMAT)] = a.Code
+		}
+
+		for j, q := range c.Qualifications {
+			var expires time.Time
+			if q.Expires != "" {
+				date, err := time.Parse(JSON_DATE_FORMAT, q.Expires)
+				if err != nil {
+					problem(fmt.Sprintf("%s.qualifications[%d].expires", path, j), "%q is not a date like 2026-01-02", q.Expires)
+					continue
+				}
+				expires = date
+			}
+
+			if availability.Qualifications == nil {
+				availability.Qualifications = make(map[string]time.Time)
+			}
+			availability.Qualifications[qualificationName(q.Name)] = expires
+		}
+
+		crew = append(crew, availability)
+	}
+
+	schedulePayload := NewSchedulePayload(crew)
+	schedulePayload.fileName = fileName
+
+	for i, p := range input.Pins {
+		path := fmt.Sprintf("pins[%d]", i)
+
+		date, err := time.Parse(JSON_DATE_FORMAT, p.Date)
+		if err != nil {
+			problem(path+".date", "%q is not a date like 2026-01-02", p.Date)
+			continue
+		}
+
+		schedulePayload.Pins = append(schedulePayload.Pins, &Pin{
+			Date:      date.Format(FULL_DATE_FORMAT),
+			Time:      p.Time,
+			Type:      p.Type,
+			FirstName: p.FirstName,
+			LastName:  p.LastName,
+			Seat:      p.Seat,
+			Source:    fmt.Sprintf("%s, %s", fileName, path),
+		})
+	}
+
+	if len(problems) > 0 {
+		return nil, nil, nil, &ValidationError{Problems: problems}
+	}
+
+	return schedulePayload, flightSchedules, days, nil
+}
+
+// Writes the crew, planning days, flights and pins a schedule is planned from as a schedule-input document, from
+// which generate --input plans the same schedule. Flights are written with their durations and requirements, so
+// they don't depend on the templates
+func exportScheduleInputJSON(s *SchedulePayload, flightSchedules *FlightSchedules, fileName string) error {
+	var (
+		document = &ScheduleInputJSON{
+			Version: JSON_FORMAT_VERSION,
+			Kind:    SCHEDULE_INPUT_KIND,
+			Crew:    []*CrewJSON{},
+			Days:    []*PlanDayJSON{},
+		}
+		dayByDate = make(map[string]*PlanDayJSON) // Key: date in FULL_DATE_FORMAT
+	)
+
+	for _, date := range dates {
+		inputDate, err := time.Parse(INPUT_DATE_FORMAT, date)
+		if err != nil {
+			return err
+		}
+
+		day := &PlanDayJSON{Date: inputDate.Format(JSON_DATE_FORMAT), Flights: []*PlanFlightJSON{}}
+		dayByDate[inputDate.Format(FULL_DATE_FORMAT)] = day
+		document.Days = append(document.Days, day)
+	}
+
+	for _, flight := range flightSchedules.Flights {
+		day, ok := dayByDate[flight.Date]
+		if !ok {
+			return fmt.Errorf("the %s %s flight on %s isn't on a planning day", flight.Time, flight.Type, flight.Date)
+		}
+		day.Flights = append(day.Flights, &PlanFlightJSON{
+			Time:     flight.Time,
+			Type:     flight.Type,
+			Duration: flight.Duration.String(),
+			Requires: flight.Requires,
+		})
+	}
+
+	for _, crew := range s.CrewAvailability {
+		c := &CrewJSON{
+			FirstName:    crew.FirstName,
+			LastName:     crew.LastName,
+			Rank:         crew.Rank,
+			Status:       crew.Status,
+			Availability: make(map[string]*AvailabilityJSON),
+		}
+		if crew.HasHours {
+			hours := crew.Hours
+			c.Hours = &hours
+		}
+
+		for day, code := range crew.Codes {
+			date, err := time.Parse(FULL_DATE_FORMAT, day)
+			if err != nil {
+				return err
+			}
+			c.Availability[date.Format(JSON_DATE_FORMAT)] = &AvailabilityJSON{Available: crew.Availabilty[day], Code: code}
+		}
+
+		names := []string{}
+		for name := range crew.Qualifications {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			q := &QualificationJSON{Name: name}
+			if expires := crew.Qualifications[name]; !expires.IsZero() {
+				q.Expires = expires.Format(JSON_DATE_FORMAT)
+			}
+			c.Qualifications = append(c.Qualifications, q)
+		}
+
+		document.Crew = append(document.Crew, c)
+	}
+
+	for _, pin := range s.Pins {
+		date, err := time.Parse(FULL_DATE_FORMAT, pin.Date)
+		if err != nil {
+			return err
+		}
+		document.Pins = append(document.Pins, &PinJSON{
+			Date:      date.Format(JSON_DATE_FORMAT),
+			Time:      pin.Time,
+			Type:      pin.Type,
+			FirstName: pin.FirstName,
+			LastName:  pin.LastName,
+			Seat:      pin.Seat,
+		})
+	}
+
+	return writeJSONDocument(fileName, document)
+}
+
+func exportJSONResult(flightSchedules *FlightSchedules, fileName string) error {
+	var (
+		document = &FlightSchedulesJSON{
+			Version:  JSON_FORMAT_VERSION,
+			Kind:     FLIGHT_SCHEDULES_KIND,
+			Flights:  []*FlightJSON{},
+			Unfilled: []*UnfilledSeatJSON{},
+		}
+		flightIndexes = make(map[*Flight]int)
+	)
+
+	for i, flight := range flightSchedules.Flights {
+		flightIndexes[flight] = i
+
+		date, err := time.Parse(FULL_DATE_FORMAT, flight.Date)
+		if err != nil {
+			return err
+		}
+
+		f := &FlightJSON{
+			Date:     date.Format(JSON_DATE_FORMAT),
+			Time:     flight.Time,
+			Type:     flight.Type,
+			Duration: flight.Duration.String(),
+			Requires: flight.Requires,
+			Seats:    flight.Seats,
+			MaxSeats: flight.MaxSeats,
+			Crew:     []*CrewMemberJSON{},
+		}
+		for _, crew := range flight.crew() {
+			f.Crew = append(f.Crew, crewMemberJSON(crew))
+		}
+
+		document.Flights = append(document.Flights, f)
+	}
+
+	for _, seat := range flightSchedules.Unfilled {
+		s := &UnfilledSeatJSON{Flight: seat.FlightIndex, Status: seat.Status, Exclusions: []*ExclusionJSON{}}
+		for _, exclusion := range seat.Exclusions {
+			e := &ExclusionJSON{Reason: exclusion.Reason}
+			if exclusion.Crew != nil {
+				e.Crew = crewMemberJSON(exclusion.Crew)
+			}
+			s.Exclusions = append(s.Exclusions, e)
+		}
+		document.Unfilled = append(document.Unfilled, s)
+	}
+
+	for _, change := range flightSchedules.Changes {
+		date, err := time.Parse(FULL_DATE_FORMAT, change.Flight.Date)
+		if err != nil {
+			return err
+		}
+
+		c := &ChangeJSON{Date: date.Format(JSON_DATE_FORMAT), Time: change.Flight.Time, Type: change.Flight.Type, Kind: change.Kind, Crew: crewMemberJSON(change.Crew), Details: change.Details}
+		if flightIndex, ok := flightIndexes[change.Flight]; ok {
+			c.Flight = &flightIndex
+		}
+		document.Changes = append(document.Changes, c)
+	}
+
+	for _, day := range flightSchedules.CutOff {
+		date, err := time.Parse(FULL_DATE_FORMAT, day.Date)
+		if err != nil {
+			return err
+		}
+		document.CutOff = append(document.CutOff, &CutOffDayJSON{Date: date.Format(JSON_DATE_FORMAT), Unfilled: day.Unfilled, MinUnfilled: day.MinUnfilled})
+	}
+
+	return writeJSONDocument(fileName, document)
+}
+
+func crewMemberJSON(crew *CrewMember) *CrewMemberJSON {
+	return &CrewMemberJSON{
+		FirstName: crew.FirstName,
+		LastName:  crew.LastName,
+		Rank:      crew.Rank,
+		Status:    crew.Status,
+		Seat:      crew.Seat,
+		Pinned:    crew.Pinned,
+	}
+}
+
+// Reads flight schedules back from a document written by exportJSONResult
+func flightSchedulesFromJSON(fileName string) (*FlightSchedules, error) {
+	document := &FlightSchedulesJSON{}
+	if err := decodeJSONDocument(fileName, FLIGHT_SCHEDULES_KIND, document); err != nil {
+		return nil, err
+	}
+
+	var (
+		problems        = []error{}
+		flightSchedules = &FlightSchedules{Flights: []*Flight{}, Unfilled: []*UnfilledSeat{}}
+	)
+
+	problem := func(path string, format string, a ...interface{}) {
+		problems = append(problems, &JSONError{File: fileName, Path: path, Problem: fmt.Sprintf(format, a...)})
+	}
+
+	crewMember := func(c *CrewMemberJSON) *CrewMember {
+		return &CrewMember{FirstName: c.FirstName, LastName: c.LastName, Rank: c.Rank, Status: c.Status, Seat: c.Seat, Pinned: c.Pinned}
+	}
+
+	// The schema has already checked the document's shape, so only what it can't express is checked here
+	for i, f := range document.Flights {
+		path := fmt.Sprintf("flights[%d]", i)
+
+		flight := &Flight{Type: f.Type, Time: f.Time, Seats: f.Seats, MaxSeats: f.MaxSeats, Requires: f.Requires, Rule: flightConfig.flightCrewRule(f.Type, f.Time)}
+		flightSchedules.Flights = append(flightSchedules.Flights, flight)
+
+		date, err := time.Parse(JSON_DATE_FORMAT, f.Date)
+		if err != nil {
+			problem(path+".date", "%q is not a date like 2026-01-02", f.Date)
+		} else {
+			flight.Date = date.Format(FULL_DATE_FORMAT)
+		}
+		if flight.Duration, err = time.ParseDuration(f.Duration); err != nil {
+			problem(path+".duration", "%q is not a duration like 1h30m", f.Duration)
+		}
+
+		for _, c := range f.Crew {
+			seatCrew(flight, crewMember(c))
+		}
+
+		if flight.Seats == nil {
+			flight.fitSeats(nil)
+		}
+	}
+
+	for i, s := range document.Unfilled {
+		path := fmt.Sprintf("unfilled[%d]", i)
+		if s.Flight >= len(flightSchedules.Flights) {
+			problem(path+".flight", "%d is not the index of a flight", s.Flight)
+			continue
+		}
+
+		flight := flightSchedules.Flights[s.Flight]
+		seat := &UnfilledSeat{FlightIndex: s.Flight, Date: flight.Date, Time: flight.Time, Type: flight.Type, Status: s.Status, Exclusions: []*Exclusion{}}
+		for _, e := range s.Exclusions {
+			exclusion := &Exclusion{Reason: e.Reason}
+			if e.Crew != nil {
+				exclusion.Crew = crewMember(e.Crew)
+			}
+			seat.Exclusions = append(seat.Exclusions, exclusion)
+		}
+
+		flightSchedules.Unfilled = append(flightSchedules.Unfilled, seat)
+	}
+
+	for i, c := range document.Changes {
+		path := fmt.Sprintf("changes[%d]", i)
+
+		// Crew taken off a flight that's no longer in the schedule only have the flight's date, time and type
+		var flight *Flight
+		if c.Flight != nil {
+			if *c.Flight >= len(flightSchedules.Flights) {
+				problem(path+".flight", "%d is not the index of a flight", *c.Flight)
+				continue
+			}
+			flight = flightSchedules.Flights[*c.Flight]
+		} else {
+			date, err := time.Parse(JSON_DATE_FORMAT, c.Date)
+			if err != nil {
+				problem(path+".date", "%q is not a date like 2026-01-02", c.Date)
+				continue
+			}
+			flight = &Flight{Date: date.Format(FULL_DATE_FORMAT), Time: c.Time, Type: c.Type}
+		}
+
+		flightSchedules.Changes = append(flightSchedules.Changes, &Change{Flight: flight, Crew: crewMember(c.Crew), Kind: c.Kind, Details: c.Details})
+	}
+
+	for i, c := range document.CutOff {
+		date, err := time.Parse(JSON_DATE_FORMAT, c.Date)
+		if err != nil {
+			problem(fmt.Sprintf("cut_off[%d].date", i), "%q is not a date like 2026-01-02", c.Date)
+			continue
+		}
+		flightSchedules.CutOff = append(flightSchedules.CutOff, &CutOffDay{Date: date.Format(FULL_DATE_FORMAT), Unfilled: c.Unfilled, MinUnfilled: c.MinUnfilled})
+	}
+
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+
+	return flightSchedules, nil
+}
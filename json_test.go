@@ -0,0 +1,346 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const testScheduleInput = `{
+	"version": 1,
+	"kind": "schedule-input",
+	"crew": [
+		{
+			"first_name": "Jane",
+			"last_name": "Doe",
+			"rank": "CW3",
+			"status": "PC",
+			"hours": 120.5,
+			"availability": {"2026-03-02": {"available": true}, "2026-03-03": {"available": false, "code": "F"}},
+			"qualifications": [{"name": "mtp", "expires": "2026-06-30"}, {"name": "NVG"}]
+		},
+		{
+			"first_name": "John",
+			"last_name": "Roe",
+			"rank": "CW2",
+			"status": "PI",
+			"availability": {"2026-03-02": {"available": true, "code": "AMR"}}
+		}
+	],
+	"days": [
+		{
+			"date": "2026-03-02",
+			"flights": [
+				{"time": "0900", "type": "MAINTENANCE", "duration": "2h", "requires": {"PC": ["MTP"]}},
+				{"time": "1300", "type": "NORMAL", "duration": "1h30m"}
+			]
+		},
+		{"date": "2026-03-03", "flights": []}
+	],
+	"pins": [{"date": "2026-03-02", "time": "0900", "type": "MAINTENANCE", "first_name": "John", "last_name": "Roe", "seat": "PC"}]
+}`
+
+// Sets the planning dates to the days, the way planning from a JSON input does
+func setJSONTestDates(t *testing.T, days []time.Time) {
+	savedDates, savedFlights := dates, numFlightsByDate
+	t.Cleanup(func() { dates, numFlightsByDate = savedDates, savedFlights })
+
+	dates = []string{}
+	numFlightsByDate = make(map[string]int)
+	for _, day := range days {
+		addPlanningDate(day, 0)
+	}
+}
+
+func TestScheduleInputRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	original := filepath.Join(dir, "original.json")
+	if err := os.WriteFile(original, []byte(testScheduleInput), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	imported, flightSchedules, days, err := scheduleInputFromJSON(original)
+	if err != nil {
+		t.Fatal(err)
+	}
+	setJSONTestDates(t, days)
+
+	exported := filepath.Join(dir, "exported.json")
+	if err := exportScheduleInputJSON(imported, flightSchedules, exported); err != nil {
+		t.Fatal(err)
+	}
+	reimported, reimportedFlights, reimportedDays, err := scheduleInputFromJSON(exported)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(reimported.CrewAvailability, imported.CrewAvailability) {
+		t.Errorf("crew = %+v, want %+v", reimported.CrewAvailability, imported.CrewAvailability)
+	}
+	if !reflect.DeepEqual(reimportedFlights, flightSchedules) {
+		t.Errorf("flights = %+v, want %+v", reimportedFlights.Flights, flightSchedules.Flights)
+	}
+	if !reflect.DeepEqual(reimportedDays, days) {
+		t.Errorf("days = %v, want %v", reimportedDays, days)
+	}
+
+	// Pins only differ in where they were read from
+	for _, pins := range [][]*Pin{imported.Pins, reimported.Pins} {
+		for _, pin := range pins {
+			pin.Source = ""
+		}
+	}
+	if !reflect.DeepEqual(reimported.Pins, imported.Pins) {
+		t.Errorf("pins = %+v, want %+v", reimported.Pins, imported.Pins)
+	}
+	if len(imported.Pins) != 1 || imported.Pins[0].Seat != "PC" {
+		t.Errorf("pins = %+v, want John Roe pinned in a PC seat", imported.Pins)
+	}
+
+	again := filepath.Join(dir, "again.json")
+	if err := exportScheduleInputJSON(reimported, reimportedFlights, again); err != nil {
+		t.Fatal(err)
+	}
+	assertSameJSONFiles(t, again, exported)
+}
+
+func TestFlightSchedulesRoundTrip(t *testing.T) {
+	var (
+		jane = &CrewMember{FirstName: "Jane", LastName: "Doe", Rank: "CW3", Status: "PC", Pinned: true}
+		john = &CrewMember{FirstName: "John", LastName: "Roe", Rank: "CW2", Status: "PC", Seat: "PI"}
+		gone = &CrewMember{FirstName: "Kim", LastName: "Zoe", Rank: "SGT", Status: "FE"}
+	)
+	flight := &Flight{
+		Type:     "MAINTENANCE",
+		Date:     "Mar 02 26",
+		Time:     "0900",
+		Duration: 2 * time.Hour,
+		Requires: map[string][]string{"PC": {"MTP"}},
+		Seats:    map[string]int{"PC": 1, "PI": 1, "FE": 1},
+		MaxSeats: map[string]int{"PC": 1, "PI": 2, "FE": 1},
+		PC:       jane,
+		PIs:      []*CrewMember{john},
+	}
+	written := &FlightSchedules{
+		Flights: []*Flight{flight},
+		Unfilled: []*UnfilledSeat{{
+			FlightIndex: 0,
+			Status:      "FE",
+			Exclusions:  []*Exclusion{{Crew: gone, Reason: "unavailable (code L) in Troop to Task"}, {Reason: "no FEs in Troop to Task.xlsx"}},
+		}},
+		Changes: []*Change{
+			{Flight: flight, Crew: john, Kind: CHANGE_ADDED},
+			{Flight: &Flight{Type: "NORMAL", Date: "Mar 02 26", Time: "1300"}, Crew: gone, Kind: CHANGE_REMOVED, Details: "unavailable (code L) in Troop to Task"},
+		},
+		CutOff: []*CutOffDay{{Date: "Mar 02 26", Unfilled: 1}},
+	}
+
+	dir := t.TempDir()
+	original := filepath.Join(dir, "original.json")
+	if err := exportJSONResult(written, original); err != nil {
+		t.Fatal(err)
+	}
+
+	imported, err := flightSchedulesFromJSON(original)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exported := filepath.Join(dir, "exported.json")
+	if err := exportJSONResult(imported, exported); err != nil {
+		t.Fatal(err)
+	}
+	reimported, err := flightSchedulesFromJSON(exported)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(reimported, imported) {
+		t.Errorf("flight schedules = %+v, want %+v", reimported, imported)
+	}
+	if got := imported.Flights[0].PIs; len(got) != 1 || *got[0] != *john {
+		t.Errorf("PIs = %+v, want %+v", got, john)
+	}
+	assertSameJSONFiles(t, exported, original)
+}
+
+func assertSameJSONFiles(t *testing.T, got string, want string) {
+	t.Helper()
+
+	gotData, err := os.ReadFile(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantData, err := os.ReadFile(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(gotData, wantData) {
+		t.Errorf("%s =\n%s\nwant\n%s", filepath.Base(got), gotData, wantData)
+	}
+}
+
+func TestScheduleInputSchemaProblems(t *testing.T) {
+	tests := []struct {
+		name     string
+		crew     string
+		flights  string
+		pins     string
+		problems []string
+	}{
+		{
+			name:     "blank qualification",
+			flights:  `[{"time": "0900", "type": "MAINTENANCE", "requires": {"PC": [" "]}}]`,
+			problems: []string{`days[0].flights[0].requires["PC"][0]: is blank`},
+		},
+		{
+			name:     "unknown seat status in requires",
+			flights:  `[{"time": "0900", "type": "MAINTENANCE", "requires": {"XO": ["MTP"]}}]`,
+			problems: []string{`days[0].flights[0].requires["XO"]: "XO" is not one of PC, PI, FE, CE`},
+		},
+		{
+			name:     "crew on an input flight",
+			flights:  `[{"time": "0900", "type": "MAINTENANCE", "crew": []}]`,
+			problems: []string{`days[0].flights[0].crew: is not a known field`},
+		},
+		{
+			name:     "time and type",
+			flights:  `[{"time": "9am", "type": "SIM"}]`,
+			problems: []string{`days[0].flights[0].time: "9am" is not a time like 0900`, `days[0].flights[0].type: "SIM" is not one of MAINTENANCE, TRAINING, NORMAL`},
+		},
+		{
+			name:     "null and blank crew",
+			crew:     `[null, {"first_name": "  ", "last_name": "Doe", "rank": "CW2", "status": "PI", "hours": -1, "availability": {}}]`,
+			problems: []string{`crew[0]: is null`, `crew[1].first_name: is blank`, `crew[1].hours: is -1; the minimum is 0`},
+		},
+		{
+			name:     "missing field and bad date key",
+			crew:     `[{"first_name": "Jane", "last_name": "Doe", "rank": "CW2", "status": "PI", "availability": {"Jan 5": {}}}]`,
+			problems: []string{`crew[0].availability["Jan 5"]: "Jan 5" is not a date like 2026-01-02`, `crew[0].availability["Jan 5"].available: is missing`},
+		},
+		{
+			name:     "pin substituting in an unknown seat",
+			pins:     `[{"date": "2026-03-02", "time": "0900", "first_name": "Jane", "last_name": "Doe", "seat": "XO"}]`,
+			problems: []string{`pins[0].seat: "XO" is not one of PC, PI, FE, CE`},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			document := map[string]json.RawMessage{
+				"version": json.RawMessage(`1`),
+				"kind":    json.RawMessage(`"schedule-input"`),
+				"crew":    json.RawMessage(`[]`),
+				"days":    json.RawMessage(`[{"date": "2026-03-02", "flights": ` + test.flights + `}]`),
+			}
+			if test.flights == "" {
+				document["days"] = json.RawMessage(`[{"date": "2026-03-02", "flights": []}]`)
+			}
+			if test.crew != "" {
+				document["crew"] = json.RawMessage(test.crew)
+			}
+			if test.pins != "" {
+				document["pins"] = json.RawMessage(test.pins)
+			}
+			data, err := json.Marshal(document)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			fileName := filepath.Join(t.TempDir(), "input.json")
+			if err := os.WriteFile(fileName, data, 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			_, _, _, err = scheduleInputFromJSON(fileName)
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("err = %v, want a ValidationError", err)
+			}
+
+			problems := []string{}
+			for _, problem := range validationErr.Problems {
+				var jsonErr *JSONError
+				if !errors.As(problem, &jsonErr) {
+					t.Fatalf("problem %v isn't a JSONError", problem)
+				}
+				problems = append(problems, jsonErr.Path+": "+jsonErr.Problem)
+			}
+			if !reflect.DeepEqual(problems, test.problems) {
+				t.Errorf("problems = %q, want %q", problems, test.problems)
+			}
+		})
+	}
+}
+
+// The keywords validateJSONSchema understands; the schemas can't use any others, or they'd go unchecked
+var supportedSchemaKeywords = map[string]bool{
+	"$schema": true, "title": true, "description": true, "$defs": true, "$ref": true,
+	"type": true, "const": true, "enum": true,
+	"properties": true, "required": true, "additionalProperties": true, "propertyNames": true,
+	"items": true, "minItems": true, "maxItems": true,
+	"pattern": true, "minLength": true, "minimum": true,
+}
+
+func TestJSONSchemasOnlyUseSupportedKeywords(t *testing.T) {
+	for kind := range jsonSchemaFiles {
+		data, err := jsonSchema(kind)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var root map[string]interface{}
+		if err := json.Unmarshal(data, &root); err != nil {
+			t.Fatal(err)
+		}
+		v := &jsonSchemaValidator{root: root}
+
+		var walk func(path string, schema map[string]interface{})
+		walk = func(path string, schema map[string]interface{}) {
+			for keyword, value := range schema {
+				if !supportedSchemaKeywords[keyword] {
+					t.Errorf("%s %s uses unsupported keyword %q", kind, path, keyword)
+				}
+
+				switch keyword {
+				case "$ref":
+					if v.resolve(value.(string)) == nil {
+						t.Errorf("%s %s refers to missing %s", kind, path, value)
+					}
+				case "properties", "$defs":
+					for name, property := range value.(map[string]interface{}) {
+						walk(path+"/"+keyword+"/"+name, property.(map[string]interface{}))
+					}
+				case "items", "propertyNames", "additionalProperties":
+					if sub, ok := value.(map[string]interface{}); ok {
+						walk(path+"/"+keyword, sub)
+					}
+				}
+			}
+		}
+		walk("#", root)
+	}
+}
+
+func TestScheduleInputSchemaCapsPlanningDays(t *testing.T) {
+	data, err := jsonSchema(SCHEDULE_INPUT_KIND)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schema struct {
+		Properties struct {
+			Days struct {
+				MaxItems int `json:"maxItems"`
+			} `json:"days"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatal(err)
+	}
+	if schema.Properties.Days.MaxItems != MAX_PLANNING_DAYS {
+		t.Errorf("days.maxItems = %d, want MAX_PLANNING_DAYS (%d)", schema.Properties.Days.MaxItems, MAX_PLANNING_DAYS)
+	}
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Checks a document against the published schema for its kind
+type jsonSchemaValidator struct {
+	root     map[string]interface{}
+	fileName string
+	problems []error
+}
+
+// Every way the document breaks the schema for its kind, with paths like crew[0].availability["2026-01-05"]
+func validateJSONSchema(kind string, fileName string, data []byte) ([]error, error) {
+	schema, err := jsonSchema(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &jsonSchemaValidator{fileName: fileName, problems: []error{}}
+	if err := json.Unmarshal(schema, &v.root); err != nil {
+		return nil, fmt.Errorf("%s schema: %s", kind, err)
+	}
+
+	var document interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&document); err != nil {
+		return nil, &JSONError{File: fileName, Problem: err.Error()}
+	}
+
+	v.validate("", document, v.root)
+	return v.problems, nil
+}
+
+func (v *jsonSchemaValidator) problem(path string, format string, a ...interface{}) {
+	v.problems = append(v.problems, &JSONError{File: v.fileName, Path: path, Problem: fmt.Sprintf(format, a...)})
+}
+
+func (v *jsonSchemaValidator) validate(path string, value interface{}, schema map[string]interface{}) {
+	if ref, ok := schema["$ref"].(string); ok {
+		v.validate(path, value, v.resolve(ref))
+	}
+
+	if want, ok := schema["type"].(string); ok && !isJSONType(value, want) {
+		if value == nil {
+			v.problem(path, "is null")
+		} else {
+			v.problem(path, "is %s; expected %s", jsonTypeName(value), jsonTypeArticle(want))
+		}
+		return
+	}
+
+	if want, ok := schema["const"]; ok && !jsonEqual(value, want) {
+		v.problem(path, "is %s; expected %s", jsonText(value), jsonText(want))
+	}
+	if options, ok := schema["enum"].([]interface{}); ok {
+		v.validateEnum(path, value, options)
+	}
+
+	switch value := value.(type) {
+	case map[string]interface{}:
+		v.validateObject(path, value, schema)
+	case []interface{}:
+		v.validateArray(path, value, schema)
+	case string:
+		v.validateString(path, value, schema)
+	case json.Number:
+		if minimum, ok := schema["minimum"].(float64); ok {
+			if n, err := value.Float64(); err == nil && n < minimum {
+				v.problem(path, "is %s; the minimum is %g", value, minimum)
+			}
+		}
+	}
+}
+
+// Looks up a reference to one of the schema's own definitions, e.g. #/$defs/date, or nil if there's no such
+// definition
+func (v *jsonSchemaValidator) resolve(ref string) map[string]interface{} {
+	defs, _ := v.root["$defs"].(map[string]interface{})
+	def, _ := defs[strings.TrimPrefix(ref, "#/$defs/")].(map[string]interface{})
+	return def
+}
+
+func (v *jsonSchemaValidator) validateEnum(path string, value interface{}, options []interface{}) {
+	texts := []string{}
+	for _, option := range options {
+		if jsonEqual(value, option) {
+			return
+		}
+		texts = append(texts, fmt.Sprint(option))
+	}
+	v.problem(path, "%s is not one of %s", jsonText(value), strings.Join(texts, ", "))
+}
+
+func (v *jsonSchemaValidator) validateObject(path string, value map[string]interface{}, schema map[string]interface{}) {
+	properties, _ := schema["properties"].(map[string]interface{})
+
+	if required, ok := schema["required"].([]interface{}); ok {
+		for _, name := range required {
+			if _, ok := value[name.(string)]; !ok {
+				v.problem(jsonPropertyPath(path, name.(string)), "is missing")
+			}
+		}
+	}
+
+	names := []string{}
+	for name := range value {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if propertyNames, ok := schema["propertyNames"].(map[string]interface{}); ok {
+			v.validate(fmt.Sprintf("%s[%q]", path, name), name, propertyNames)
+		}
+
+		if property, ok := properties[name].(map[string]interface{}); ok {
+			v.validate(jsonPropertyPath(path, name), value[name], property)
+			continue
+		}
+
+		switch additional := schema["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				v.problem(jsonPropertyPath(path, name), "is not a known field")
+			}
+		case map[string]interface{}:
+			v.validate(fmt.Sprintf("%s[%q]", path, name), value[name], additional)
+		}
+	}
+}
+
+func (v *jsonSchemaValidator) validateArray(path string, value []interface{}, schema map[string]interface{}) {
+	if minItems, ok := schema["minItems"].(float64); ok && float64(len(value)) < minItems {
+		v.problem(path, "has %d item(s); at least %g are needed", len(value), minItems)
+	}
+	if maxItems, ok := schema["maxItems"].(float64); ok && float64(len(value)) > maxItems {
+		v.problem(path, "has %d item(s); at most %g are allowed", len(value), maxItems)
+	}
+
+	if items, ok := schema["items"].(map[string]interface{}); ok {
+		for i, item := range value {
+			v.validate(fmt.Sprintf("%s[%d]", path, i), item, items)
+		}
+	}
+}
+
+// A string that's too short or doesn't match is reported as blank when it's only spaces, or against the
+// description of the schema that rejected it, e.g. "A date like 2026-01-02"
+func (v *jsonSchemaValidator) validateString(path string, value string, schema map[string]interface{}) {
+	ok := true
+	if minLength, has := schema["minLength"].(float64); has && float64(len([]rune(value))) < minLength {
+		ok = false
+	}
+	if pattern, has := schema["pattern"].(string); has && !regexp.MustCompile(pattern).MatchString(value) {
+		ok = false
+	}
+	if ok {
+		return
+	}
+
+	description, _ := schema["description"].(string)
+	switch {
+	case strings.TrimSpace(value) == "":
+		v.problem(path, "is blank")
+	case description != "":
+		v.problem(path, "%q is not %s", value, strings.ToLower(description[:1])+description[1:])
+	default:
+		v.problem(path, "%q isn't allowed here", value)
+	}
+}
+
+func jsonPropertyPath(path string, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func isJSONType(value interface{}, want string) bool {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		return want == "object"
+	case []interface{}:
+		return want == "array"
+	case string:
+		return want == "string"
+	case bool:
+		return want == "boolean"
+	case json.Number:
+		if want == "integer" {
+			_, err := value.Int64()
+			return err == nil
+		}
+		return want == "number"
+	}
+	return want == "null"
+}
+
+func jsonTypeName(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}:
+		return "an object"
+	case []interface{}:
+		return "an array"
+	case string:
+		return "a string"
+	case bool:
+		return "a boolean"
+	case json.Number:
+		return "a number"
+	}
+	return "null"
+}
+
+func jsonTypeArticle(name string) string {
+	if name == "object" || name == "array" || name == "integer" {
+		return "an " + name
+	}
+	return "a " + name
+}
+
+// Whether a decoded document value equals one from the schema, where numbers are float64 rather than json.Number
+func jsonEqual(value interface{}, want interface{}) bool {
+	if n, ok := value.(json.Number); ok {
+		f, err := n.Float64()
+		return err == nil && f == want
+	}
+	return value == want
+}
+
+func jsonText(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
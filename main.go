@@ -1,10 +1,13 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -16,6 +19,7 @@ import (
 const (
 	CREW_FILE         = "info.xlsx"
 	SCHEDULE_FILE     = "Troop to Task.xlsx"
+	OUTPUT_FILE       = "files/FlightSchedules.xlsx"
 	FULL_DATE_FORMAT  = "Jan 02 06"
 	INPUT_DATE_FORMAT = "1/2/2006"
 
@@ -26,27 +30,9 @@ const (
 	INFO_FIRST_LAST_NAME_COL = 0
 	INFO_RANK_COL            = 1
 	INFO_HOURS_COL           = 2
-
-	NUMBER_OF_MAINTENANCE_FLIGHTS = 1
-	NUMBER_OF_TRAINING_FLIGHTS    = 3
 )
 
 var (
-	daysByMonth = map[string]int{
-		"Jan": 31,
-		"Feb": 28, //TODO: Or 29
-		"Mar": 31,
-		"Apr": 30,
-		"May": 31,
-		"Jun": 30,
-		"Jul": 31,
-		"Aug": 31,
-		"Sep": 30,
-		"Oct": 31,
-		"Nov": 30,
-		"Dec": 31,
-	}
-
 	statusWhiteList = map[string]bool{
 		"PCs": true, //TODO: Normalize these to be lowercase
 		"PIs": true,
@@ -54,32 +40,18 @@ var (
 		"CEs": true,
 	}
 
-	canFlyMap = map[string]bool{
-		"":    true,
-		"F":   true,
-		"AMR": true,
-	}
-
-	rawDataRegexp = regexp.MustCompile(`\[\$\-[0-9]+\]([A-Za-z\\\-[0-9]+)`)
-
-	timesByIndex = map[int]string{
-		0: "0900", //MAINTENANCE
-		1: "0800", //TRAINING
-		2: "1000", //TRAINING
-		3: "1100", //TRAINING
-		4: "1200", //NORMAL
-		5: "1200", //NORMAL
-		6: "1700", //NORMAL
-	}
-
-	flightTypesByIndex = map[int]string{
-		0: "MAINTENANCE",
-		1: "TRAINING",
-		2: "TRAINING",
-		3: "TRAINING",
-		4: "NORMAL",
-		5: "NORMAL",
-		6: "NORMAL",
+	rawDataRegexp   = regexp.MustCompile(`\[\$\-[0-9]+\]([A-Za-z\\\-[0-9]+)`)
+	headerDayRegexp = regexp.MustCompile(`^\s*([0-9]{1,2})[^0-9A-Za-z]*([A-Za-z]*)\s*$`) // e.g. 01 Wed, 1\nW, 31
+
+	headerMonthLayouts = []string{
+		"Jan-06",
+		"Jan-2006",
+		"Jan 06",
+		"Jan 2006",
+		"January-06",
+		"January 2006",
+		"1/2/06",
+		"1/2/2006",
 	}
 
 	sheetHeading = []string{
@@ -90,22 +62,30 @@ var (
 		"Rank",
 		"First Name",
 		"Last Name",
+		"Substituting For",
 	}
 
-	mainwin          *ui.Window
-	numFlightsByDate = make(map[string]int)
-	dates            = []string{}
-	inputComplete    bool
-	fullCrew         = []string{}
-	piCrew           = []string{}
-	pcCrew           = []string{}
-	feCrew           = []string{}
-	ceCrew           = []string{}
+	mainwin            *ui.Window
+	numFlightsByDate   = make(map[string]int)
+	fixedFlightsByDate = make(map[string]map[string]int) //Key: date; Value: maintenance and training flights by type, when picked by hand
+	dates              = []string{}
+	inputComplete      bool
 )
 
 /*********Primary Structs*********/
 type SchedulePayload struct {
 	CrewAvailability []*CrewAvailability
+	Warnings         []string             // Problems with the input that don't stop a schedule from being made
+	RecentFlights    map[string]int       // Key: hours key; Value: flights flown in the weeks before this schedule
+	Pins             []*Pin               // Crew put on flights by hand
+	Pairing          *PairingRules        // Who crew should and shouldn't fly with; nil without a pairings file
+	Previous         map[*Flight][]string // Key: flight being re-planned; Value: hours keys of its crew on the previous schedule
+
+	fileName      string // Troop to Task or the schedule input the crew were read from
+	sheetName     string
+	problems      []error // Rows that couldn't be read
+	solved        bool
+	inputWarnings int // How many of the warnings came before the first solve
 }
 
 type CrewAvailability struct {
@@ -114,30 +94,50 @@ type CrewAvailability struct {
 	Rank        string
 	Status      string
 	Availabilty map[string]bool
+	Codes       map[string]string //Key: Date (format: Jan 01 06); Value: availability code from Troop to Task
+	Hours       float64           //Accumulated flight hours from info.xlsx
+	HasHours    bool              //False when the crew member isn't in info.xlsx
+	Row         int               //Zero based row in Troop to Task
+
+	Qualifications map[string]time.Time //Key: qualification, e.g. MTP; Value: last day it's current, zero if it doesn't lapse
 }
 
 // type Schedule struct {
 // 	AvailabilityByDate map[string]bool //Key: Date (format: Jan 01 06); Value: crew member is or is not available
 // }
 
-// type CrewPayload struct {
-// 	CrewMembers []*CrewMember
-// }
+type CrewPayload struct {
+	CrewMembers    []*CrewMember
+	Qualifications Qualifications // From the optional qualifications sheet
+
+	problems []error
+}
 
 /*********Secondary Structs*********/
 
 type FlightSchedules struct {
-	Flights []*Flight
+	Flights  []*Flight
+	Unfilled []*UnfilledSeat     // Seats the solver couldn't crew with the given availability
+	Reviewed bool                // Crew were changed by hand after the solver ran
+	Changes  []*Change           // Differences from the previous schedule, when re-planning
+	Crew     []*CrewAvailability // Everyone who could be scheduled; nil when read back from a written schedule
+	Pairing  *PairingRules       // Rules the crew were paired by; nil when there were none
+	CutOff   []*CutOffDay        // Days the solver couldn't prove it found the best roster for
 }
 
 type Flight struct {
-	Type string //MAINTENANCE, TRAINING, or NORMAL
-	Date string
-	Time string
-	PC   *CrewMember
-	PIs  []*CrewMember
-	FE   *CrewMember
-	CEs  []*CrewMember // No CE required for maintainence flights
+	Type     string //MAINTENANCE, TRAINING, or NORMAL
+	Date     string
+	Time     string
+	Duration time.Duration
+	Requires map[string][]string //Key: crew status; Value: qualifications everyone in those seats needs
+	Rule     *CrewRule           //Crew its template needs, or nil for its type's
+	Seats    map[string]int      //Key: crew status; Value: number of seats the chosen crew composition needs
+	MaxSeats map[string]int      //Key: crew status; Value: most crew the chosen composition can take
+	PC       *CrewMember
+	PIs      []*CrewMember
+	FEs      []*CrewMember
+	CEs      []*CrewMember // No CE required for maintainence flights
 }
 
 type CrewMember struct {
@@ -145,6 +145,9 @@ type CrewMember struct {
 	LastName  string
 	Rank      string
 	Status    string
+	Hours     float64
+	Pinned    bool   // Put on the flight by hand rather than by the solver
+	Seat      string // Status of the seat they fill when it isn't their own, e.g. PI for a PC substituting for one
 }
 
 /*
@@ -155,9 +158,13 @@ type CrewMember struct {
 	- Also sometimes there is more than one PI, and more than 1 FE or CE because of training, so if you can add multiple people into slots that'd be helpful too
 */
 
-//Input: SchedulePayload (list of crew availability)
-//Output: scheduled flights
+// Input: SchedulePayload (list of crew availability)
+// Output: scheduled flights
 func main() {
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(os.Args[1:]))
+	}
+
 	ui.Main(setupUI)
 }
 
@@ -173,14 +180,18 @@ func setupUI() {
 	})
 
 	mainwin.SetMargined(true)
-	tab := ui.NewTab()
-	mainwin.SetChild(tab)
-
-	tab.Append("Choose a Date", makeDatePage())
-	tab.SetMargined(0, true)
+	showDatePage()
 
 	mainwin.Show()
 
+	config, err := loadFlightConfig(FLIGHT_CONFIG_FILE)
+	if err != nil {
+		ui.MsgBoxError(mainwin, "Unable to load flight templates", err.Error())
+		ui.Quit()
+		return
+	}
+	flightConfig = config
+
 	ticker := time.NewTicker(5 * time.Second)
 	quit := make(chan struct{})
 	go func() {
@@ -188,17 +199,31 @@ func setupUI() {
 			select {
 			case <-ticker.C:
 				if inputComplete {
-					schedulePayload, err := payloadsFromXLSX([]string{ /*CREW_FILE, */ SCHEDULE_FILE})
-					fatalIf(err)
+					options := NewGenerateOptions()
+					if _, err := os.Stat(CREW_FILE); err == nil {
+						options.CrewFile = CREW_FILE
+					}
+					if _, err := os.Stat(PAIRING_FILE); err == nil {
+						options.PairingFile = PAIRING_FILE
+					}
 
-					flightSchedules, err := schedulePayload.calculateFlightSchedules()
-					fatalIf(err)
+					inputComplete = false
 
-					err = exportXLSXResult(flightSchedules)
-					fatalIf(err)
+					schedulePayload, flightSchedules, err := planSchedule(options)
+					if err != nil {
+						ui.QueueMain(func() {
+							ui.MsgBoxError(mainwin, "Unable to generate flight schedules", err.Error())
+							showDatePage()
+						})
+						break
+					}
 
-					ui.Quit()
-					break
+					ui.QueueMain(func() {
+						if len(schedulePayload.Warnings) > 0 {
+							ui.MsgBox(mainwin, "Warnings", strings.Join(schedulePayload.Warnings, "\n"))
+						}
+						showReviewPage(schedulePayload, flightSchedules, options)
+					})
 				}
 			case <-quit:
 				ticker.Stop()
@@ -208,24 +233,53 @@ func setupUI() {
 	}()
 }
 
+// Starts over from the date picker, forgetting any dates already picked
+func showDatePage() {
+	dates = []string{}
+	numFlightsByDate = make(map[string]int)
+	fixedFlightsByDate = make(map[string]map[string]int)
+
+	tab := ui.NewTab()
+	mainwin.SetChild(tab)
+
+	tab.Append("Choose a Date", makeDatePage())
+	tab.SetMargined(0, true)
+}
+
 func makeDatePage() ui.Control {
 	vbox := ui.NewVerticalBox()
 	vbox.SetPadded(true)
 
-	vbox.Append(ui.NewLabel("Flight schedules will be generated a week from the date you choose."), false)
+	vbox.Append(ui.NewLabel("Flight schedules will be generated for every day from the first date to the last."), false)
+
+	startPicker := ui.NewDatePicker()
+	endPicker := ui.NewDatePicker()
+	endPicker.SetTime(time.Now().AddDate(0, 0, DEFAULT_PLANNING_DAYS-1))
 
-	datePicker := ui.NewDatePicker()
-	vbox.Append(datePicker, false)
+	form := ui.NewForm()
+	form.SetPadded(true)
+	form.Append("First day", startPicker, false)
+	form.Append("Last day", endPicker, false)
+	vbox.Append(form, false)
 
 	button := ui.NewButton("Next")
 	button.OnClicked(func(*ui.Button) {
-		date := datePicker.Time()
+		start, end := startPicker.Time(), endPicker.Time()
+		days := int(calendarDay(end).Sub(calendarDay(start)).Hours()/24) + 1
 
-		for i := 0; i < 7; i++ {
-			dateString := fmt.Sprintf("%d/%d/%d", date.Month(), date.Day(), date.Year())
-			numFlightsByDate[dateString] = 3 // 3 by default
-			dates = append(dates, dateString)
+		if days < 1 {
+			ui.MsgBoxError(mainwin, "Invalid dates", "The last day can't be before the first day.")
+			return
+		}
+		if days > MAX_PLANNING_DAYS {
+			ui.MsgBoxError(mainwin, "Invalid dates", fmt.Sprintf("Flight schedules can cover at most %d days.", MAX_PLANNING_DAYS))
+			return
+		}
 
+		date := start
+		for i := 0; i < days; i++ {
+			addPlanningDate(date, flightConfig.defaultNormalFlights(date))
+			fixedFlightsByDate[planningDateString(date)] = flightConfig.defaultFixedFlights(date)
 			date = date.AddDate(0, 0, 1)
 		}
 
@@ -242,150 +296,275 @@ func makeDatePage() ui.Control {
 	return vbox
 }
 
+func addPlanningDate(date time.Time, flights int) {
+	dateString := planningDateString(date)
+	numFlightsByDate[dateString] = flights
+	dates = append(dates, dateString)
+}
+
+func planningDateString(date time.Time) string {
+	return fmt.Sprintf("%d/%d/%d", date.Month(), date.Day(), date.Year())
+}
+
+// Midnight UTC on the same calendar day, so days can be counted without daylight saving getting in the way
+func calendarDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
 func makeFlightNumberPage() ui.Control {
 	vbox := ui.NewVerticalBox()
 	vbox.SetPadded(true)
 
-	vbox.Append(ui.NewLabel("Choose the number of normal flights for each day (excluding 1 maintenance flight and 3 training sims which are always scheduled)"), false)
+	vbox.Append(ui.NewLabel("Choose the number of flights of each type for each day"), false)
 
-	/*****         0          *****/
-	date0 := dates[0]
-	flights := numFlightsByDate[date0]
-	hbox := ui.NewHorizontalBox()
+	grid := ui.NewGrid()
+	grid.SetPadded(true)
 
-	hbox.Append(ui.NewLabel(fmt.Sprintf("%s:", date0)), false)
+	for col, heading := range []string{"Date", "Maintenance", "Training", "Normal"} {
+		grid.Append(ui.NewLabel(heading), col, 0, 1, 1, false, ui.AlignStart, false, ui.AlignCenter)
+	}
 
-	numFlightsInput0 := ui.NewSpinbox(0, 100)
-	numFlightsInput0.SetValue(flights)
-	numFlightsInput0.OnChanged(func(*ui.Spinbox) {
-		numFlightsByDate[date0] = numFlightsInput0.Value()
-	})
-	hbox.Append(numFlightsInput0, false)
+	for i, date := range dates {
+		date := date
+		row := i + 1
 
-	vbox.Append(hbox, false)
+		inputDate, err := time.Parse(INPUT_DATE_FORMAT, date)
+		if err != nil {
+			ui.MsgBoxError(mainwin, "Invalid date", err.Error())
+			continue
+		}
+		grid.Append(ui.NewLabel(inputDate.Format("Mon Jan 2")), 0, row, 1, 1, false, ui.AlignStart, false, ui.AlignCenter)
 
-	/*****         1          *****/
-	date1 := dates[1]
-	flights = numFlightsByDate[date1]
-	hbox = ui.NewHorizontalBox()
+		for col, flightType := range []string{"MAINTENANCE", "TRAINING"} {
+			flightType := flightType
 
-	hbox.Append(ui.NewLabel(fmt.Sprintf("%s:", date1)), false)
+			numFlightsInput := ui.NewSpinbox(0, 100)
+			numFlightsInput.SetValue(fixedFlightsByDate[date][flightType])
+			numFlightsInput.OnChanged(func(*ui.Spinbox) {
+				fixedFlightsByDate[date][flightType] = numFlightsInput.Value()
+			})
+			grid.Append(numFlightsInput, col+1, row, 1, 1, false, ui.AlignFill, false, ui.AlignCenter)
+		}
 
-	numFlightsInput1 := ui.NewSpinbox(0, 100)
-	numFlightsInput1.SetValue(flights)
-	numFlightsInput1.OnChanged(func(*ui.Spinbox) {
-		numFlightsByDate[date1] = numFlightsInput1.Value()
-	})
-	hbox.Append(numFlightsInput1, false)
+		numFlightsInput := ui.NewSpinbox(0, 100)
+		numFlightsInput.SetValue(numFlightsByDate[date])
+		numFlightsInput.OnChanged(func(*ui.Spinbox) {
+			numFlightsByDate[date] = numFlightsInput.Value()
+		})
+		grid.Append(numFlightsInput, 3, row, 1, 1, false, ui.AlignFill, false, ui.AlignCenter)
+	}
 
-	vbox.Append(hbox, false)
+	vbox.Append(grid, false)
+
+	button := ui.NewButton("Done")
+	button.OnClicked(func(*ui.Button) {
+		tab := ui.NewTab()
+		mainwin.SetChild(tab)
 
-	/*****         2          *****/
-	date2 := dates[2]
-	flights = numFlightsByDate[date2]
-	hbox = ui.NewHorizontalBox()
+		tab.Append("Generating", makeGeneratingPage())
+		tab.SetMargined(0, true)
 
-	hbox.Append(ui.NewLabel(fmt.Sprintf("%s:", date2)), false)
+		tab.Show()
 
-	numFlightsInput2 := ui.NewSpinbox(0, 100)
-	numFlightsInput2.SetValue(flights)
-	numFlightsInput2.OnChanged(func(*ui.Spinbox) {
-		numFlightsByDate[date2] = numFlightsInput2.Value()
+		inputComplete = true
 	})
-	hbox.Append(numFlightsInput2, false)
 
-	vbox.Append(hbox, false)
+	vbox.Append(button, false)
 
-	/*****         3          *****/
-	date3 := dates[3]
-	flights = numFlightsByDate[date3]
-	hbox = ui.NewHorizontalBox()
+	return vbox
+}
 
-	hbox.Append(ui.NewLabel(fmt.Sprintf("%s:", date3)), false)
+func makeGeneratingPage() ui.Control {
+	vbox := ui.NewVerticalBox()
+	vbox.SetPadded(true)
 
-	numFlightsInput3 := ui.NewSpinbox(0, 100)
-	numFlightsInput3.SetValue(flights)
-	numFlightsInput3.OnChanged(func(*ui.Spinbox) {
-		numFlightsByDate[date3] = numFlightsInput3.Value()
-	})
-	hbox.Append(numFlightsInput3, false)
+	vbox.Append(ui.NewLabel("Flight schedules are being generated."), false)
+	vbox.Append(ui.NewLabel("They will be shown for review when complete."), false)
 
-	vbox.Append(hbox, false)
+	return vbox
+}
 
-	/*****         4          *****/
-	date4 := dates[4]
-	flights = numFlightsByDate[date4]
-	hbox = ui.NewHorizontalBox()
+type GenerateOptions struct {
+	ScheduleFile string
+	CrewFile     string // Optional
+	OutputFile   string
+	Layout       string // LAYOUT_LIST or LAYOUT_BOARD; workbooks only
+	CalendarFile string // Optional .ics file, written along with a calendar per crew member
+	HistoryFile  string // Optional
+	HistoryWeeks int
+	PairingFile  string // Optional
+	Pins         []*Pin // Added to any pins from Troop to Task
+	InputCopy    string // Optional schedule-input document to write the crew, days, flights and pins planned from
+}
 
-	hbox.Append(ui.NewLabel(fmt.Sprintf("%s:", date4)), false)
+func NewGenerateOptions() *GenerateOptions {
+	return &GenerateOptions{
+		ScheduleFile: SCHEDULE_FILE,
+		OutputFile:   OUTPUT_FILE,
+		Layout:       LAYOUT_LIST,
+		HistoryFile:  HISTORY_FILE,
+		HistoryWeeks: DEFAULT_HISTORY_WEEKS,
+	}
+}
 
-	numFlightsInput4 := ui.NewSpinbox(0, 100)
-	numFlightsInput4.SetValue(flights)
-	numFlightsInput4.OnChanged(func(*ui.Spinbox) {
-		numFlightsByDate[date4] = numFlightsInput4.Value()
-	})
-	hbox.Append(numFlightsInput4, false)
+// Plans the schedule and exports it straight away, without a review
+func generateSchedule(options *GenerateOptions) (*SchedulePayload, *FlightSchedules, error) {
+	schedulePayload, flightSchedules, err := planSchedule(options)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	vbox.Append(hbox, false)
+	err = exportSchedule(schedulePayload, flightSchedules, options)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	/*****         5          *****/
-	date5 := dates[5]
-	flights = numFlightsByDate[date5]
-	hbox = ui.NewHorizontalBox()
+	return schedulePayload, flightSchedules, nil
+}
 
-	hbox.Append(ui.NewLabel(fmt.Sprintf("%s:", date5)), false)
+// Reads Troop to Task (and info.xlsx, if given) and schedules the planning dates, taking the history into account.
+// A JSON schedule input brings its own crew and flights instead
+func planSchedule(options *GenerateOptions) (*SchedulePayload, *FlightSchedules, error) {
+	if isJSONFile(options.ScheduleFile) {
+		return planScheduleFromJSON(options)
+	}
 
-	numFlightsInput5 := ui.NewSpinbox(0, 100)
-	numFlightsInput5.SetValue(flights)
-	numFlightsInput5.OnChanged(func(*ui.Spinbox) {
-		numFlightsByDate[date5] = numFlightsInput5.Value()
-	})
-	hbox.Append(numFlightsInput5, false)
+	schedulePayload, err := readSchedulePayload(options, nil)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	vbox.Append(hbox, false)
+	flightSchedules, err := schedulePayload.calculateFlightSchedules()
+	if err != nil {
+		return nil, nil, err
+	}
 
-	/*****         6          *****/
-	date6 := dates[6]
-	flights = numFlightsByDate[date6]
-	hbox = ui.NewHorizontalBox()
+	return schedulePayload, flightSchedules, nil
+}
 
-	hbox.Append(ui.NewLabel(fmt.Sprintf("%s:", date6)), false)
+// Everything the solver needs besides the flights: availability, hours, pins and recent flights. Troop to Task
+// doesn't need columns for the frozen dates, which are left as they are
+func readSchedulePayload(options *GenerateOptions, frozen map[string]bool) (*SchedulePayload, error) {
+	var (
+		schedulePayload *SchedulePayload
+		err             error
+	)
+	if isJSONFile(options.ScheduleFile) {
+		schedulePayload, _, _, err = payloadsFromJSON(options.ScheduleFile, options.CrewFile)
+	} else {
+		schedulePayload, err = payloadsFromXLSX(options.ScheduleFile, options.CrewFile, frozen)
+	}
+	if err != nil {
+		return nil, err
+	}
 
-	numFlightsInput6 := ui.NewSpinbox(0, 100)
-	numFlightsInput6.SetValue(flights)
-	numFlightsInput6.OnChanged(func(*ui.Spinbox) {
-		numFlightsByDate[date6] = numFlightsInput6.Value()
-	})
-	hbox.Append(numFlightsInput6, false)
+	err = prepareSchedulePayload(schedulePayload, options)
+	if err != nil {
+		return nil, err
+	}
 
-	vbox.Append(hbox, false)
+	return schedulePayload, nil
+}
 
-	button := ui.NewButton("Done")
-	button.OnClicked(func(*ui.Button) {
-		tab := ui.NewTab()
-		mainwin.SetChild(tab)
+// Plans the days and flights listed in a JSON schedule input
+func planScheduleFromJSON(options *GenerateOptions) (*SchedulePayload, *FlightSchedules, error) {
+	schedulePayload, flightSchedules, days, err := payloadsFromJSON(options.ScheduleFile, options.CrewFile)
+	if err != nil {
+		return nil, nil, err
+	}
 
-		tab.Append("Generating", makeGeneratingPage())
-		tab.SetMargined(0, true)
+	dates = []string{}
+	numFlightsByDate = make(map[string]int)
+	for _, day := range days {
+		addPlanningDate(day, 0)
+	}
 
-		tab.Show()
+	err = prepareSchedulePayload(schedulePayload, options)
+	if err != nil {
+		return nil, nil, err
+	}
 
-		inputComplete = true
-	})
+	err = schedulePayload.solveFlightSchedules(flightSchedules, nil)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	vbox.Append(button, false)
+	return schedulePayload, flightSchedules, nil
+}
 
-	return vbox
+// Adds the pins given for this run, the pairing rules and the flights crew flew in the weeks before it
+func prepareSchedulePayload(schedulePayload *SchedulePayload, options *GenerateOptions) error {
+	schedulePayload.Pins = append(schedulePayload.Pins, options.Pins...)
+
+	if options.PairingFile != "" {
+		pairing, err := loadPairingRules(options.PairingFile)
+		if err != nil {
+			return err
+		}
+		schedulePayload.Pairing = pairing
+		schedulePayload.Warnings = append(schedulePayload.Warnings, pairing.unknownCrew(schedulePayload.CrewAvailability, options.PairingFile, options.ScheduleFile)...)
+	}
+
+	if options.HistoryFile == "" {
+		return nil
+	}
+
+	history, err := loadHistory(options.HistoryFile)
+	if err != nil {
+		return err
+	}
+
+	start, err := planningStart()
+	if err != nil {
+		return err
+	}
+	schedulePayload.RecentFlights = history.recentFlights(start, options.HistoryWeeks)
+
+	return nil
 }
 
-func makeGeneratingPage() ui.Control {
-	vbox := ui.NewVerticalBox()
-	vbox.SetPadded(true)
+// Writes the schedule, as JSON if the output file name ends in .json, and the calendars and a copy of the input
+// if asked for. Then records it in the history
+func exportSchedule(schedulePayload *SchedulePayload, flightSchedules *FlightSchedules, options *GenerateOptions) error {
+	var err error
+	if isJSONFile(options.OutputFile) {
+		err = exportJSONResult(flightSchedules, options.OutputFile)
+	} else {
+		err = exportXLSXResult(flightSchedules, options.OutputFile, options.Layout)
+	}
+	if err != nil {
+		return err
+	}
 
-	vbox.Append(ui.NewLabel("Flight schedules are being generated."), false)
-	vbox.Append(ui.NewLabel("This window will close when complete."), false)
+	if options.CalendarFile != "" {
+		err = exportICSResult(flightSchedules, options.CalendarFile)
+		if err != nil {
+			return err
+		}
+	}
 
-	return vbox
+	if options.InputCopy != "" {
+		err = exportScheduleInputJSON(schedulePayload, flightSchedules, options.InputCopy)
+		if err != nil {
+			return err
+		}
+	}
+
+	if options.HistoryFile == "" {
+		return nil
+	}
+
+	history, err := loadHistory(options.HistoryFile)
+	if err != nil {
+		return err
+	}
+
+	err = history.record(flightSchedules)
+	if err != nil {
+		return err
+	}
+
+	return history.save(options.HistoryFile)
 }
 
 func NewSchedulePayload(crewAvailability []*CrewAvailability) *SchedulePayload {
@@ -394,11 +573,11 @@ func NewSchedulePayload(crewAvailability []*CrewAvailability) *SchedulePayload {
 	}
 }
 
-// func NewCrewPayload(crewMembers []*CrewMember) *CrewPayload {
-// 	return &CrewPayload{
-// 		CrewMembers: crewMembers,
-// 	}
-// }
+func NewCrewPayload(crewMembers []*CrewMember) *CrewPayload {
+	return &CrewPayload{
+		CrewMembers: crewMembers,
+	}
+}
 
 func addSheetHeading(sheet *xlsx.Sheet, heading []string) {
 	row := sheet.AddRow()
@@ -408,10 +587,17 @@ func addSheetHeading(sheet *xlsx.Sheet, heading []string) {
 	}
 }
 
-func exportXLSXResult(flightSchedules *FlightSchedules) error {
+func exportXLSXResult(flightSchedules *FlightSchedules, fileName string, layout string) error {
 	file := xlsx.NewFile()
 
-	sheet, err := file.AddSheet("Flights")
+	if layout == LAYOUT_BOARD {
+		err := addBoardSheet(file, flightSchedules)
+		if err != nil {
+			return err
+		}
+	}
+
+	sheet, err := file.AddSheet(FLIGHTS_SHEET)
 	if err != nil {
 		return err
 	}
@@ -445,8 +631,8 @@ func exportXLSXResult(flightSchedules *FlightSchedules) error {
 			row = sheet.AddRow()
 		}
 
-		if flight.FE != nil {
-			addSingleCrew(row, flight.FE)
+		if flight.FEs != nil && len(flight.FEs) > 0 {
+			addMultipleCrew(sheet, row, flight.FEs)
 			row = sheet.AddRow()
 		}
 
@@ -456,11 +642,35 @@ func exportXLSXResult(flightSchedules *FlightSchedules) error {
 		}
 	}
 
-	if _, err := os.Stat("files/"); os.IsNotExist(err) {
-		os.Mkdir("files/", 0700)
+	err = addGapsSheet(file, flightSchedules)
+	if err != nil {
+		return err
 	}
 
-	err = file.Save("files/FlightSchedules.xlsx")
+	err = addSummarySheet(file, flightSchedules)
+	if err != nil {
+		return err
+	}
+
+	err = addByCrewSheet(file, flightSchedules)
+	if err != nil {
+		return err
+	}
+
+	if flightSchedules.Changes != nil {
+		err = addChangesSheet(file, flightSchedules)
+		if err != nil {
+			return err
+		}
+	}
+
+	if dir := filepath.Dir(fileName); dir != "." {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return err
+		}
+	}
+
+	err = file.Save(fileName)
 	if err != nil {
 		return err
 	}
@@ -483,6 +693,8 @@ func addSingleCrew(row *xlsx.Row, crew *CrewMember) {
 	cell.Value = crew.FirstName
 	cell = row.AddCell()
 	cell.Value = crew.LastName
+	cell = row.AddCell()
+	cell.Value = crew.Seat
 }
 
 func addMultipleCrew(sheet *xlsx.Sheet, row *xlsx.Row, crewMembers []*CrewMember) {
@@ -505,149 +717,53 @@ func addMultipleCrew(sheet *xlsx.Sheet, row *xlsx.Row, crewMembers []*CrewMember
 		cell.Value = crew.FirstName
 		cell = row.AddCell()
 		cell.Value = crew.LastName
+		cell = row.AddCell()
+		cell.Value = crew.Seat
 	}
 }
 
-// By default, we need
+// Seats are filled day by day with a branch and bound search (see solver.go), which crews every seat the
+// day's availability allows and leaves the fewest seats unfilled otherwise, unless it runs out of search nodes.
+// Days where it does are listed in FlightSchedules.CutOff and warned about. Seats that can't be filled are
+// listed in FlightSchedules.Unfilled
 func (s *SchedulePayload) calculateFlightSchedules() (*FlightSchedules, error) {
-	var (
-		crewHasFlight          = make(map[string]bool)
-		currentFlightDate      string
-		crewHasAFlightThisWeek = map[string]map[string]bool{ //Key: status; Value: list of crew names
-			"PI": make(map[string]bool),
-			"PC": make(map[string]bool),
-			"FE": make(map[string]bool),
-			"CE": make(map[string]bool),
-		}
-	)
-
 	flightSchedules, err := initializeFlightSchedules()
 	if err != nil {
 		return nil, err
 	}
 
-	for i, flight := range flightSchedules.Flights {
-		if currentFlightDate != flight.Date { //clear map when the date changes
-			for k := range crewHasFlight {
-				delete(crewHasFlight, k)
-			}
-		}
-
-		currentFlightDate = flight.Date
-		for _, crew := range s.CrewAvailability {
-			crewName := fmt.Sprintf("%s %s", crew.FirstName, crew.LastName)
-
-			if available, ok := crew.Availabilty[flight.Date]; ok && available && !crewHasFlight[crewName] && !crewHasAFlightThisWeek[crew.Status][crewName] { // Crew member is available for that day :)
-				spotOccupied := isSpotOccupied(flightSchedules, crew.Status, flight.Type, i)
-				if !spotOccupied {
-					switch crew.Status {
-					case "PC":
-						flightSchedules.Flights[i].PC = &CrewMember{
-							FirstName: crew.FirstName,
-							LastName:  crew.LastName,
-							Rank:      crew.Rank,
-							Status:    crew.Status,
-						}
-						break
-					case "PI":
-						flightSchedules.Flights[i].PIs = append(flightSchedules.Flights[i].PIs, &CrewMember{
-							FirstName: crew.FirstName,
-							LastName:  crew.LastName,
-							Rank:      crew.Rank,
-							Status:    crew.Status,
-						})
-						break
-					case "FE":
-						flightSchedules.Flights[i].FE = &CrewMember{
-							FirstName: crew.FirstName,
-							LastName:  crew.LastName,
-							Rank:      crew.Rank,
-							Status:    crew.Status,
-						}
-						break
-					case "CE":
-						flightSchedules.Flights[i].CEs = append(flightSchedules.Flights[i].CEs, &CrewMember{
-							FirstName: crew.FirstName,
-							LastName:  crew.LastName,
-							Rank:      crew.Rank,
-							Status:    crew.Status,
-						})
-						break
-					}
-					crewHasFlight[crewName] = true
-					crewHasAFlightThisWeek[crew.Status][crewName] = true
-
-					if len(fullCrew) == len(crewHasAFlightThisWeek) { //Clear when we get at least one flight for everyone this week
-						for k := range crewHasAFlightThisWeek {
-							delete(crewHasAFlightThisWeek, k)
-						}
-					}
-					if 0.9*float64(len(piCrew)) >= float64(len(crewHasAFlightThisWeek["PI"])) {
-						crewHasAFlightThisWeek["PI"] = make(map[string]bool)
-					}
-					if 0.9*float64(len(pcCrew)) >= float64(len(crewHasAFlightThisWeek["PC"])) {
-						crewHasAFlightThisWeek["PC"] = make(map[string]bool)
-
-					}
-					if 0.9*float64(len(feCrew)) >= float64(len(crewHasAFlightThisWeek["FE"])) {
-						crewHasAFlightThisWeek["FE"] = make(map[string]bool)
-
-					}
-					if 0.9*float64(len(ceCrew)) >= float64(len(crewHasAFlightThisWeek["CE"])) {
-						crewHasAFlightThisWeek["CE"] = make(map[string]bool)
-					}
-				}
-			}
-		}
+	err = s.solveFlightSchedules(flightSchedules, nil)
+	if err != nil {
+		return nil, err
 	}
 
 	return flightSchedules, nil
 }
 
-func isSpotOccupied(flightSchedules *FlightSchedules, crewStatus string, flightType string, flightIndex int) bool {
-	flight := flightSchedules.Flights[flightIndex]
+// Crews every day of the flight schedules except the frozen ones, whose crew are left as they are
+func (s *SchedulePayload) solveFlightSchedules(flightSchedules *FlightSchedules, frozen map[string]bool) error {
+	pinned, pinnedSeats, err := resolvePins(s, flightSchedules)
+	if err != nil {
+		return err
+	}
 
-	switch crewStatus {
-	case "PC":
-		return flight.PC != nil
-		break
-	case "PI":
-		switch flight.Type {
-		case "MAINTENANCE", "NORMAL":
-			return flight.PIs != nil && len(flight.PIs) == 1
-			break
-		case "TRAINING":
-			if flightIndex%2 == 0 {
-				return flight.PIs != nil && len(flight.PIs) == 2
-			} else {
-				return flight.PIs != nil && len(flight.PIs) == 1
-			}
-			break
-		}
-		break
-	case "FE":
-		return flight.FE != nil
-		break
-	case "CE":
-		switch flight.Type {
-		case "MAINTENANCE":
-			return true
-			break
-		case "TRAINING":
-			if flightIndex%2 == 0 {
-				return flight.CEs != nil && len(flight.CEs) == 1
-			} else {
-				return flight.CEs != nil && len(flight.CEs) == 3
-			}
-			break
-		case "NORMAL":
-			return flight.CEs != nil && len(flight.CEs) == 1
-			break
-		}
-		break
+	solveSchedule(s, flightSchedules, frozen, pinned, pinnedSeats)
+
+	flightSchedules.Crew = s.CrewAvailability
+	flightSchedules.Pairing = s.Pairing
+	explainUnfilledSeats(s, flightSchedules)
+
+	// Each solve replaces the warnings the last one added, keeping those about the input
+	if !s.solved {
+		s.inputWarnings, s.solved = len(s.Warnings), true
 	}
+	s.Warnings = append([]string{}, s.Warnings[:s.inputWarnings]...)
+	s.Warnings = append(s.Warnings, cutOffWarnings(flightSchedules.CutOff)...)
+	s.Warnings = append(s.Warnings, restViolations(flightSchedules, "")...)
+	s.Warnings = append(s.Warnings, pairingViolations(flightSchedules, "")...)
+	s.Warnings = append(s.Warnings, expiringQualifications(s.CrewAvailability, flightSchedules)...)
 
-	return false
+	return nil
 }
 
 func initializeFlightSchedules() (*FlightSchedules, error) {
@@ -659,89 +775,117 @@ func initializeFlightSchedules() (*FlightSchedules, error) {
 			return nil, err
 		}
 
-		inputDateString := fmt.Sprintf("%d/%d/%d", inputDate.Month(), inputDate.Day(), inputDate.Year())
-		fullDate := inputDate.Format(FULL_DATE_FORMAT)
+		inputDateString := planningDateString(inputDate)
 
-		flights := numFlightsByDate[inputDateString]
-
-		for i := 0; i < flights+NUMBER_OF_MAINTENANCE_FLIGHTS+NUMBER_OF_TRAINING_FLIGHTS; i++ {
-			flightSchedules.Flights = append(flightSchedules.Flights, &Flight{
-				Type: flightTypesByIndex[i],
-				Date: fullDate,
-				Time: timesByIndex[i],
-			})
+		flights, err := flightConfig.flightsForDay(inputDate, numFlightsByDate[inputDateString], fixedFlightsByDate[inputDateString])
+		if err != nil {
+			return nil, err
 		}
+
+		flightSchedules.Flights = append(flightSchedules.Flights, flights...)
 	}
 
 	return flightSchedules, nil
 }
 
-func payloadsFromXLSX(fileNames []string) (*SchedulePayload, error) {
-	var (
-		schedulePayload *SchedulePayload
-		// crewPayload     *CrewPayload
-		err error
-	)
+// Reads Troop to Task and, unless crewFileName is empty, joins in the crew hours from info.xlsx
+func payloadsFromXLSX(scheduleFileName string, crewFileName string, frozen map[string]bool) (*SchedulePayload, error) {
+	var crewPayload *CrewPayload
+
+	log.Println("Reading", scheduleFileName)
+	file, err := openWorkbook(scheduleFileName)
+	if err != nil {
+		return nil, err
+	}
+
+	schedulePayload, err := schedulePayloadFromXLSX(file, scheduleFileName)
+	if err != nil {
+		return nil, err
+	}
 
-	for _, fileName := range fileNames {
-		log.Println("Reading", fileName)
-		file, err := xlsx.OpenFile(fileName)
+	if crewFileName != "" {
+		log.Println("Reading", crewFileName)
+		file, err := openWorkbook(crewFileName)
 		if err != nil {
 			return nil, err
 		}
 
-		switch fileName {
-		case SCHEDULE_FILE:
-			schedulePayload, err = schedulePayloadFromXLSX(file)
-			fatalIf(err)
-			break
-			// case CREW_FILE:
-			// 	crewPayload, err = crewPayloadFromXLSX(file)
-			// 	fatalIf(err)
-			// 	break
+		crewPayload, err = crewPayloadFromXLSX(file, crewFileName)
+		if err != nil {
+			return nil, err
 		}
 	}
 
-	err = checkPayloadsForFunnyBusiness(schedulePayload)
+	err = checkPayloadsForFunnyBusiness(schedulePayload, scheduleFileName, crewPayload, crewFileName, frozen)
+	if err != nil {
+		return nil, err
+	}
 
-	return schedulePayload, err
-}
+	if crewPayload != nil {
+		schedulePayload.Warnings = append(schedulePayload.Warnings, joinCrewHours(schedulePayload, scheduleFileName, crewPayload, crewFileName)...)
+	}
 
-func schedulePayloadFromXLSX(file *xlsx.File) (*SchedulePayload, error) {
-	var schedulePayload *SchedulePayload
+	return schedulePayload, nil
+}
 
-	if len(file.Sheets) > 0 {
-		sheet := file.Sheets[len(file.Sheets)-1]
+func schedulePayloadFromXLSX(file *xlsx.File, fileName string) (*SchedulePayload, error) {
+	if len(file.Sheets) == 0 {
+		return nil, &SheetMissingError{File: fileName}
+	}
 
-		scheduleMap, err := getScheduleMap(sheet)
-		if err != nil {
-			return nil, err
+	// Availability is on the last sheet, not counting the optional pins sheet
+	var sheet *xlsx.Sheet
+	for i := len(file.Sheets) - 1; i >= 0 && sheet == nil; i-- {
+		if file.Sheets[i].Name != PINS_SHEET {
+			sheet = file.Sheets[i]
 		}
+	}
+	if sheet == nil {
+		return nil, &SheetMissingError{File: fileName}
+	}
 
-		schedulePayload, err = createSchedulePayload(sheet, scheduleMap)
-		if err != nil {
-			return nil, err
-		}
+	scheduleMap, err := getScheduleMap(sheet, fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	schedulePayload, err := createSchedulePayload(sheet, scheduleMap, fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	if pinsSheet, ok := file.Sheet[PINS_SHEET]; ok {
+		pins, problems := pinsFromSheet(pinsSheet, fileName)
+		schedulePayload.Pins = append(schedulePayload.Pins, pins...)
+		schedulePayload.problems = append(schedulePayload.problems, problems...)
 	}
 
 	return schedulePayload, nil
 }
 
-func createSchedulePayload(sheet *xlsx.Sheet, scheduleMap map[int]string) (*SchedulePayload, error) {
+// Reads the crew rows under each status heading. Rows that can't be read are skipped and recorded as problems
+// for checkPayloadsForFunnyBusiness to report
+func createSchedulePayload(sheet *xlsx.Sheet, scheduleMap map[int]string, fileName string) (*SchedulePayload, error) {
 	var (
 		crewAvailabilities = []*CrewAvailability{}
+		problems           = []error{}
 		currentStatus      string
 	)
 
+	cellProblem := func(row int, col int, err error) {
+		problems = append(problems, &CellError{File: fileName, Sheet: sheet.Name, Row: row, Col: col, Problem: err.Error()})
+	}
+
 	for i, row := range sheet.Rows {
 		if i < 4 {
 			continue
 		}
 
 		//Do a check for status
-		firstCellVal, err := row.Cells[0].FormattedValue()
+		firstCellVal, err := cellValue(row, 0)
 		if err != nil {
-			return nil, err
+			cellProblem(i, 0, err)
+			continue
 		}
 
 		firstCellVal = strings.TrimSpace(firstCellVal)
@@ -753,185 +897,352 @@ func createSchedulePayload(sheet *xlsx.Sheet, scheduleMap map[int]string) (*Sche
 			break
 		}
 
-		rank, err := row.Cells[RANK_COL].FormattedValue()
+		if currentStatus == "" {
+			problems = append(problems, &CellError{File: fileName, Sheet: sheet.Name, Row: i, Col: 0, Problem: "crew member listed before any PCs, PIs, FEs or CEs heading"})
+			continue
+		}
+
+		rank, err := cellValue(row, RANK_COL)
 		if err != nil {
-			return nil, err
+			cellProblem(i, RANK_COL, err)
+			continue
 		}
 
-		firstName, err := row.Cells[FIRST_NAME_COL].FormattedValue()
+		firstName, err := cellValue(row, FIRST_NAME_COL)
 		if err != nil {
-			return nil, err
+			cellProblem(i, FIRST_NAME_COL, err)
+			continue
 		}
 
-		lastName, err := row.Cells[LAST_NAME_COL].FormattedValue()
+		lastName, err := cellValue(row, LAST_NAME_COL)
 		if err != nil {
-			return nil, err
+			cellProblem(i, LAST_NAME_COL, err)
+			continue
 		}
 
 		availability := make(map[string]bool)
+		codes := make(map[string]string)
 		for j := 5; j < len(row.Cells); j++ {
 			cell := row.Cells[j]
-			avail, err := cell.FormattedValue() //availability: Everything means busy or can't fly except F, AMR, or blank
+			avail, err := cell.FormattedValue() //availability: Codes the flight config gives no window mean busy or can't fly
 			if err != nil {
-				return nil, err
+				cellProblem(i, j, err)
+				continue
 			}
 
 			if date, ok := scheduleMap[j]; ok {
-				if _, canFly := canFlyMap[avail]; canFly {
-					availability[date] = true
-				} else {
-					availability[date] = false
+				if problem := flightConfig.availabilityCodeProblem(avail); problem != "" {
+					cellProblem(i, j, errors.New(problem))
 				}
+				codes[date] = avail
+				availability[date] = flightConfig.availabilityWindow(avail) != nil
+			}
+		}
+
+		for j, date := range scheduleMap { // Blank cells past the end of a short row
+			if _, ok := availability[date]; !ok && j >= len(row.Cells) {
+				if problem := flightConfig.availabilityCodeProblem(""); problem != "" {
+					cellProblem(i, j, errors.New(problem))
+				}
+				availability[date] = flightConfig.availabilityWindow("") != nil
+				codes[date] = ""
 			}
 		}
 
 		crewAvailabilities = append(crewAvailabilities,
 			&CrewAvailability{
-				FirstName:   strings.ReplaceAll(firstName, "*", ""),
-				LastName:    strings.ReplaceAll(lastName, "*", ""),
-				Rank:        rank,
+				FirstName:   strings.TrimSpace(strings.ReplaceAll(firstName, "*", "")),
+				LastName:    strings.TrimSpace(strings.ReplaceAll(lastName, "*", "")),
+				Rank:        strings.TrimSpace(rank),
 				Status:      strings.TrimSuffix(currentStatus, "s"),
 				Availabilty: availability,
+				Codes:       codes,
+				Row:         i,
 			},
 		)
+	}
 
-		fullCrew = append(fullCrew, fmt.Sprintf("%s %s", strings.ReplaceAll(firstName, "*", ""), strings.ReplaceAll(lastName, "*", "")))
-		switch strings.TrimSuffix(currentStatus, "s") {
-		case "PC":
-			pcCrew = append(pcCrew, fmt.Sprintf("%s %s", strings.ReplaceAll(firstName, "*", ""), strings.ReplaceAll(lastName, "*", "")))
-			break
-		case "PI":
-			piCrew = append(piCrew, fmt.Sprintf("%s %s", strings.ReplaceAll(firstName, "*", ""), strings.ReplaceAll(lastName, "*", "")))
-			break
-		case "FE":
-			feCrew = append(feCrew, fmt.Sprintf("%s %s", strings.ReplaceAll(firstName, "*", ""), strings.ReplaceAll(lastName, "*", "")))
-			break
-		case "CE":
-			ceCrew = append(ceCrew, fmt.Sprintf("%s %s", strings.ReplaceAll(firstName, "*", ""), strings.ReplaceAll(lastName, "*", "")))
-			break
+	schedulePayload := NewSchedulePayload(crewAvailabilities)
+	schedulePayload.fileName = fileName
+	schedulePayload.sheetName = sheet.Name
+	schedulePayload.problems = problems
+
+	return schedulePayload, nil
+}
+
+// Works out the calendar date of every day column from the two header rows: month-year cells in the first row
+// (e.g. Jan-20) and day of month plus weekday cells in the second (e.g. 01 Wed)
+func getScheduleMap(sheet *xlsx.Sheet, fileName string) (map[int]string, error) {
+	var (
+		scheduleMap = make(map[int]string) //Return value - Key: Column of the cell that refers to that date; Value: Date (format Jan 01 06)
+		monthByCol  = make(map[int]time.Time)
+		month       time.Time
+		lastDay     int
+	)
+
+	headerError := func(row int, col int, problem string) error {
+		return &HeaderError{File: fileName, Sheet: sheet.Name, Row: row, Col: col, Problem: problem}
+	}
+
+	if len(sheet.Rows) < 2 {
+		return nil, headerError(-1, 0, "expected month and day header rows")
+	}
+
+	for j, cell := range sheet.Rows[0].Cells { // Find month-year strings, and their starting columns
+		val, err := cell.FormattedValue()
+		if err != nil {
+			return nil, headerError(0, j, err.Error())
+		}
+
+		if headerMonth, ok := parseHeaderMonth(val); ok {
+			monthByCol[j] = headerMonth
+		}
+	}
+
+	for j, cell := range sheet.Rows[1].Cells { // Find days of week and days of month in the cell below
+		if headerMonth, ok := monthByCol[j]; ok {
+			month = headerMonth
+			lastDay = 0
+		}
+
+		val, err := cell.FormattedValue()
+		if err != nil {
+			return nil, headerError(1, j, err.Error())
+		}
+
+		matches := headerDayRegexp.FindStringSubmatch(val)
+		if month.IsZero() || matches == nil {
+			continue
+		}
+
+		dayOfMonth, _ := strconv.Atoi(matches[1])
+		if dayOfMonth <= lastDay { // Next month's days without a header of their own
+			month = month.AddDate(0, 1, 0)
+		}
+		lastDay = dayOfMonth
+
+		date := time.Date(month.Year(), month.Month(), dayOfMonth, 0, 0, 0, 0, time.UTC)
+		if dayOfMonth < 1 || date.Month() != month.Month() {
+			return nil, headerError(1, j, fmt.Sprintf("%q has day %d, but %s only has %d days", val, dayOfMonth, month.Format("January 2006"), daysIn(month)))
+		}
+
+		if weekday := matches[2]; weekday != "" && !isWeekdayAbbreviation(weekday, date.Weekday()) {
+			return nil, headerError(1, j, fmt.Sprintf("%q says %s, but %s is a %s", val, weekday, date.Format("Jan 2 2006"), date.Weekday()))
 		}
+
+		scheduleMap[j] = date.Format(FULL_DATE_FORMAT)
+	}
+
+	if len(scheduleMap) == 0 {
+		return nil, headerError(-1, 0, "no dates found; expected month cells like Jan-20 in row 1 and day cells like 01 Wed in row 2")
 	}
 
-	return NewSchedulePayload(crewAvailabilities), nil
+	return scheduleMap, nil
 }
 
-func getScheduleMap(sheet *xlsx.Sheet) (map[int]string, error) {
+// Month header cells come through as either the value or the raw number format (e.g. [$-409]Jan\-20)
+func parseHeaderMonth(val string) (time.Time, bool) {
+	if rawDataRegexp.MatchString(val) {
+		val = rawDataRegexp.ReplaceAllString(val, "$1")
+	}
+	val = strings.TrimSpace(strings.ReplaceAll(val, `\`, ""))
+
+	for _, layout := range headerMonthLayouts {
+		if month, err := time.Parse(layout, val); err == nil {
+			return time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC), true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// Accepts any prefix of the weekday's name, e.g. W, Wed or Weds
+func isWeekdayAbbreviation(abbreviation string, weekday time.Weekday) bool {
+	abbreviation = strings.ToLower(abbreviation)
+	if abbreviation == "weds" {
+		abbreviation = "wed"
+	}
+
+	return strings.HasPrefix(strings.ToLower(weekday.String()), abbreviation)
+}
+
+func daysIn(month time.Time) int {
+	return time.Date(month.Year(), month.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// Spreadsheet name of a zero based cell, e.g. (1, 5) -> F2
+func cellName(row int, col int) string {
+	name := ""
+	for col++; col > 0; col = (col - 1) / 26 {
+		name = string(rune('A'+(col-1)%26)) + name
+	}
+
+	return fmt.Sprintf("%s%d", name, row+1)
+}
+
+func crewPayloadFromXLSX(file *xlsx.File, fileName string) (*CrewPayload, error) {
 	var (
-		startingColByDate = make(map[string]int)
-		scheduleMap       = make(map[int]string) //Return value - Key: Column of the cell that refers to that date; Value: Date (format Jan 01 06)
+		crewMembers = []*CrewMember{}
+		problems    = []error{}
 	)
 
+	// Hours are on the first sheet, not counting the optional qualifications sheet
+	var sheet *xlsx.Sheet
+	for i := 0; i < len(file.Sheets) && sheet == nil; i++ {
+		if file.Sheets[i].Name != QUALIFICATIONS_SHEET {
+			sheet = file.Sheets[i]
+		}
+	}
+	if sheet == nil {
+		return nil, &SheetMissingError{File: fileName}
+	}
+
 	for i, row := range sheet.Rows {
-		if i == 2 {
-			break
+		var (
+			firstName string
+			lastName  string
+			rank      string
+			hours     float64
+		)
+
+		cellProblem := func(col int, problem string) {
+			problems = append(problems, &CellError{File: fileName, Sheet: sheet.Name, Row: i, Col: col, Problem: problem})
 		}
-		for j, cell := range row.Cells {
-			val, err := cell.FormattedValue()
-			if err != nil {
-				return nil, err
-			}
 
-			if i == 0 { // Find month-year strings, and their starting columns
-				if rawDataRegexp.MatchString(val) {
-					val = rawDataRegexp.ReplaceAllString(val, "$1")
-					val = strings.ReplaceAll(val, `\`, "")
-					startingColByDate[val] = j
-				}
-			} else if i == 1 { // Find days of week and days of month in the cell below
-				runes := []rune(val)
-				dayOfMonth := string(runes[0:2])
-
-				for date, startingCol := range startingColByDate {
-					splitDate := strings.Split(date, "-")
-					if len(splitDate) == 2 {
-						month := splitDate[0] //Jan-06 -> Jan
-						year := splitDate[1]
-						if daysInMonth, ok := daysByMonth[month]; ok {
-							startCol, endCol := startingCol, startingCol+daysInMonth //TODO: Still need to handle leap year
-
-							if j >= startCol && j <= endCol {
-								fullDate := fmt.Sprintf("%s %s %s", month, dayOfMonth, year)
-								scheduleMap[j] = fullDate
-							}
-						}
-					}
-				}
-			}
+		firstLast, err := cellValue(row, INFO_FIRST_LAST_NAME_COL)
+		if err != nil {
+			cellProblem(INFO_FIRST_LAST_NAME_COL, err.Error())
+			continue
+		}
+
+		nameSplit := strings.Split(firstLast, ",")
+		if len(nameSplit) != 2 {
+			continue // Headings, totals, blank rows
 		}
+		lastName = strings.TrimSpace(nameSplit[0])
+		firstName = strings.TrimSpace(nameSplit[1])
+
+		rank, err = cellValue(row, INFO_RANK_COL)
+		if err != nil {
+			cellProblem(INFO_RANK_COL, err.Error())
+			continue
+		}
+
+		if len(row.Cells) <= INFO_HOURS_COL {
+			cellProblem(INFO_HOURS_COL, fmt.Sprintf("no hours for %s", firstLast))
+			continue
+		}
+
+		hours, err = row.Cells[INFO_HOURS_COL].Float()
+		if err != nil {
+			cellProblem(INFO_HOURS_COL, fmt.Sprintf("hours for %s aren't a number", firstLast))
+			continue
+		}
+
+		crewMembers = append(crewMembers,
+			&CrewMember{
+				FirstName: firstName,
+				LastName:  lastName,
+				Rank:      strings.TrimSpace(rank),
+				Hours:     hours,
+			},
+		)
 	}
 
-	return scheduleMap, nil
-}
+	crewPayload := NewCrewPayload(crewMembers)
+	crewPayload.problems = problems
 
-// func crewPayloadFromXLSX(file *xlsx.File) (*CrewPayload, error) {
-// 	var (
-// 		crewMembers = []*CrewMember{}
-// 	)
-
-// 	if len(file.Sheets) > 0 {
-// 		sheet := file.Sheets[0]
-// 		for _, row := range sheet.Rows {
-// 			var (
-// 				firstName string
-// 				lastName  string
-// 				rank      string
-// 				hours     float64
-// 			)
-
-// 			firstLast, err := row.Cells[INFO_FIRST_LAST_NAME_COL].FormattedValue()
-// 			if err != nil {
-// 				return nil, err
-// 			}
-
-// 			nameSplit := strings.Split(firstLast, ", ")
-// 			if len(nameSplit) == 2 {
-// 				lastName = nameSplit[0]
-// 				firstName = nameSplit[1]
-// 			}
-
-// 			rank, err = row.Cells[INFO_RANK_COL].FormattedValue()
-// 			if err != nil {
-// 				return nil, err
-// 			}
-
-// 			hours, err = row.Cells[INFO_RANK_COL].Float()
-// 			if err != nil {
-// 				return nil, err
-// 			}
-
-// 			crewMembers = append(crewMembers,
-// 				&CrewMember{
-// 					FirstName: firstName,
-// 					LastName:  lastName,
-// 					Rank:      rank,
-// 					Hours:     hours,
-// 				},
-// 			)
-// 		}
-// 	}
-
-// 	return NewCrewPayload(crewMembers), nil
-// }
+	if qualificationsSheet, ok := file.Sheet[QUALIFICATIONS_SHEET]; ok {
+		qualifications, problems := qualificationsFromSheet(qualificationsSheet, fileName)
+		crewPayload.Qualifications = qualifications
+		crewPayload.problems = append(crewPayload.problems, problems...)
+	}
 
-func checkPayloadsForFunnyBusiness(schedulePayload *SchedulePayload) error {
-	var err error
+	return crewPayload, nil
+}
+
+// Collects every problem with the parsed workbooks, rather than stopping at the first one. Frozen dates are
+// allowed to be missing from Troop to Task
+func checkPayloadsForFunnyBusiness(schedulePayload *SchedulePayload, scheduleFileName string, crewPayload *CrewPayload, crewFileName string, frozen map[string]bool) error {
+	problems := []error{}
 
 	if schedulePayload == nil {
-		err = fmt.Errorf("Error parsing %s; ", SCHEDULE_FILE)
+		problems = append(problems, fmt.Errorf("Error parsing %s", scheduleFileName))
+	} else {
+		problems = append(problems, schedulePayload.problems...)
+		problems = append(problems, checkCrewAvailability(schedulePayload, scheduleFileName, frozen)...)
+	}
+
+	if crewFileName != "" {
+		if crewPayload == nil {
+			problems = append(problems, fmt.Errorf("Error parsing %s", crewFileName))
+		} else {
+			problems = append(problems, crewPayload.problems...)
+		}
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+
+	return nil
+}
+
+func checkCrewAvailability(schedulePayload *SchedulePayload, fileName string, frozen map[string]bool) []error {
+	var (
+		problems     = []error{}
+		rowByName    = make(map[string]int)
+		crewByStatus = make(map[string]int)
+		datesListed  = make(map[string]bool)
+	)
+
+	for _, crew := range schedulePayload.CrewAvailability {
+		crewByStatus[crew.Status]++
+		for date := range crew.Availabilty {
+			datesListed[date] = true
+		}
+
+		if crew.FirstName == "" {
+			problems = append(problems, &CellError{File: fileName, Sheet: schedulePayload.sheetName, Row: crew.Row, Col: FIRST_NAME_COL, Problem: "first name is blank"})
+		}
+		if crew.LastName == "" {
+			problems = append(problems, &CellError{File: fileName, Sheet: schedulePayload.sheetName, Row: crew.Row, Col: LAST_NAME_COL, Problem: "last name is blank"})
+		}
+		if crew.Rank == "" {
+			problems = append(problems, &CellError{File: fileName, Sheet: schedulePayload.sheetName, Row: crew.Row, Col: RANK_COL, Problem: "rank is blank"})
+		}
+
+		name := crewName(crew.FirstName, crew.LastName)
+		if row, ok := rowByName[name]; ok {
+			problems = append(problems, &CellError{File: fileName, Sheet: schedulePayload.sheetName, Row: crew.Row, Col: LAST_NAME_COL, Problem: fmt.Sprintf("%s is already listed on row %d", name, row+1)})
+		} else {
+			rowByName[name] = crew.Row
+		}
+	}
+
+	for _, status := range seatStatuses {
+		if crewByStatus[status] == 0 {
+			problems = append(problems, fmt.Errorf("%s has no %ss", fileName, status))
+		}
+	}
+
+	for _, date := range dates {
+		inputDate, err := time.Parse(INPUT_DATE_FORMAT, date)
+		if err != nil {
+			problems = append(problems, err)
+			continue
+		}
+
+		if fullDate := inputDate.Format(FULL_DATE_FORMAT); !datesListed[fullDate] && !frozen[fullDate] && len(schedulePayload.CrewAvailability) > 0 {
+			problems = append(problems, fmt.Errorf("%s has no column for %s", fileName, inputDate.Format("Mon Jan 2 2006")))
+		}
 	}
-	// if crewPayload == nil {
-	// 	if err != nil {
-	// 		err = fmt.Errorf("%sError parsing %s;", err.Error(), CREW_FILE)
-	// 	} else {
-	// 		err = fmt.Errorf("Error parsing %s; ", CREW_FILE)
-	// 	}
-	// }
 
-	return err
+	return problems
 }
 
 func fatalIf(err error) {
 	if err != nil {
-		log.Fatalf(err.Error())
+		log.Fatal(err)
 	}
 }
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/tealeg/xlsx"
+)
+
+// Troop to Task header rows with the names in the first two columns, the month cell over the first day
+func headerSheet(month string, days ...string) *xlsx.Sheet {
+	file := xlsx.NewFile()
+	sheet, _ := file.AddSheet("Schedule")
+
+	monthRow, dayRow := sheet.AddRow(), sheet.AddRow()
+	for _, value := range []string{"Last Name", "First Name"} {
+		monthRow.AddCell()
+		dayRow.AddCell().Value = value
+	}
+	for i, day := range days {
+		cell := monthRow.AddCell()
+		if i == 0 {
+			cell.Value = month
+		}
+		dayRow.AddCell().Value = day
+	}
+
+	return sheet
+}
+
+func TestGetScheduleMap(t *testing.T) {
+	tests := []struct {
+		name    string
+		month   string
+		days    []string
+		want    map[int]string
+		wantErr *HeaderError
+	}{
+		{
+			name:  "December into January",
+			month: "Dec-25",
+			days:  []string{"30 Tue", "31 Wed", "01 Thu", "02 Fri"},
+			want:  map[int]string{2: "Dec 30 25", 3: "Dec 31 25", 4: "Jan 01 26", 5: "Jan 02 26"},
+		},
+		{
+			name:  "February 29 in a leap year",
+			month: "Feb-24",
+			days:  []string{"28 W", "29 Th", "1 F"},
+			want:  map[int]string{2: "Feb 28 24", 3: "Feb 29 24", 4: "Mar 01 24"},
+		},
+		{
+			name:    "February 29 outside a leap year",
+			month:   "Feb-25",
+			days:    []string{"28", "29"},
+			wantErr: &HeaderError{Row: 1, Col: 3},
+		},
+		{
+			name:  "single digit days",
+			month: "Jan-26",
+			days:  []string{"1\nTh", "2 F", "3"},
+			want:  map[int]string{2: "Jan 01 26", 3: "Jan 02 26", 4: "Jan 03 26"},
+		},
+		{
+			name:    "weekday that doesn't match the date",
+			month:   "Jan-26",
+			days:    []string{"01 Thu", "02 Sat"},
+			wantErr: &HeaderError{Row: 1, Col: 3, Problem: `"02 Sat" says Sat, but Jan 2 2026 is a Friday`},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			scheduleMap, err := getScheduleMap(headerSheet(test.month, test.days...), "schedule.xlsx")
+
+			if test.wantErr != nil {
+				var headerErr *HeaderError
+				if !errors.As(err, &headerErr) {
+					t.Fatalf("err = %v, want a HeaderError", err)
+				}
+				if headerErr.Row != test.wantErr.Row || headerErr.Col != test.wantErr.Col {
+					t.Errorf("error is for cell %s, want %s: %v", cellName(headerErr.Row, headerErr.Col), cellName(test.wantErr.Row, test.wantErr.Col), err)
+				}
+				if test.wantErr.Problem != "" && headerErr.Problem != test.wantErr.Problem {
+					t.Errorf("Problem = %q, want %q", headerErr.Problem, test.wantErr.Problem)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(scheduleMap, test.want) {
+				t.Errorf("getScheduleMap() = %v, want %v", scheduleMap, test.want)
+			}
+		})
+	}
+}
+
+func TestHeaderDayRegexp(t *testing.T) {
+	tests := []struct {
+		val     string
+		day     string
+		weekday string
+	}{
+		{"01 Wed", "01", "Wed"},
+		{"1\nW", "1", "W"},
+		{"31", "31", ""},
+		{" 9-Thu ", "9", "Thu"},
+		{"Total", "", ""},
+		{"123", "", ""},
+	}
+
+	for _, test := range tests {
+		matches := headerDayRegexp.FindStringSubmatch(test.val)
+		day, weekday := "", ""
+		if matches != nil {
+			day, weekday = matches[1], matches[2]
+		}
+		if day != test.day || weekday != test.weekday {
+			t.Errorf("headerDayRegexp on %q = (%q, %q), want (%q, %q)", test.val, day, weekday, test.day, test.weekday)
+		}
+	}
+}
@@ -0,0 +1,362 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+const (
+	PAIRING_FILE    = "pairings.json"
+	PAIRING_PENALTY = 20 // Cost per crew member on a flight without anyone they prefer to fly with
+)
+
+/*
+Who crew should and shouldn't fly with, read from pairings.json, e.g.
+
+	{
+		"must_pair": [{"crew": "Doe, Jane", "with": ["Roe, Rick", "Poe, Sam"]}],
+		"prefer_pair": [{"crew": "Moe, Lee", "with": ["Roe, Rick"]}],
+		"never_pair": [{"crew": "Doe, Jane", "with": ["Zoe, Kim"]}],
+		"progression": {"instructor": "IP", "crew": ["Moe, Lee"]}
+	}
+
+Crew under "must_pair" only fly on flights with at least one of the crew they're listed with, and crew under
+"never_pair" never share a flight with them. "prefer_pair" is the same as "must_pair", except the scheduler
+seats them without a partner when it has to, and counts it in the summary. Crew in "progression" only fly with
+someone else on the flight who holds the "instructor" qualification from info.xlsx. Pinned crew are kept where
+they're pinned, so their "must_pair" and "progression" rules are only preferences.
+*/
+type PairingRules struct {
+	MustPair    []*PairingRule   `json:"must_pair"`
+	PreferPair  []*PairingRule   `json:"prefer_pair"`
+	NeverPair   []*PairingRule   `json:"never_pair"`
+	Progression *ProgressionRule `json:"progression,omitempty"`
+
+	names       map[string]string          // Key: hours key; Value: name as written in the file
+	must        map[string][]string        // Key: hours key; Value: hours keys of the crew they must fly with
+	prefer      map[string][]string        // Key: hours key; Value: hours keys of the crew they prefer to fly with
+	never       map[string]map[string]bool // Both ways round
+	progression map[string]bool
+}
+
+type PairingRule struct {
+	Crew string   `json:"crew"` // "Last, First"
+	With []string `json:"with"`
+}
+
+type ProgressionRule struct {
+	Instructor string   `json:"instructor"` // Qualification, e.g. IP
+	Crew       []string `json:"crew"`
+}
+
+// A flight a crew member was seated on without anyone they prefer to fly with
+type brokenPreference struct {
+	Flight  *Flight
+	Crew    *CrewMember
+	Prefers string
+}
+
+func loadPairingRules(fileName string) (*PairingRules, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := &PairingRules{}
+	if err := json.Unmarshal(data, rules); err != nil {
+		return nil, fmt.Errorf("Error parsing %s: %s", fileName, err)
+	}
+
+	if err := rules.validate(); err != nil {
+		return nil, fmt.Errorf("Error in %s: %s", fileName, err)
+	}
+
+	return rules, nil
+}
+
+func (r *PairingRules) validate() error {
+	r.names = make(map[string]string)
+	r.must = make(map[string][]string)
+	r.prefer = make(map[string][]string)
+	r.never = make(map[string]map[string]bool)
+	r.progression = make(map[string]bool)
+
+	key := func(path string, name string) (string, error) {
+		parts := strings.SplitN(name, ",", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+			return "", fmt.Errorf("%s is %q; expected a name like \"Doe, Jane\"", path, name)
+		}
+
+		key := hoursKey(parts[1], parts[0])
+		r.names[key] = fmt.Sprintf("%s, %s", strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]))
+		return key, nil
+	}
+
+	pairs := func(field string, rules []*PairingRule, add func(crew string, with string)) error {
+		for i, rule := range rules {
+			path := fmt.Sprintf("%s[%d]", field, i)
+			if rule == nil {
+				return fmt.Errorf("%s is empty", path)
+			}
+
+			crew, err := key(path+".crew", rule.Crew)
+			if err != nil {
+				return err
+			}
+			if len(rule.With) == 0 {
+				return fmt.Errorf("%s.with names nobody", path)
+			}
+
+			for j, name := range rule.With {
+				with, err := key(fmt.Sprintf("%s.with[%d]", path, j), name)
+				if err != nil {
+					return err
+				}
+				if with == crew {
+					return fmt.Errorf("%s pairs %s with themselves", path, name)
+				}
+				add(crew, with)
+			}
+		}
+		return nil
+	}
+
+	err := pairs("must_pair", r.MustPair, func(crew string, with string) {
+		r.must[crew] = append(r.must[crew], with)
+	})
+	if err != nil {
+		return err
+	}
+
+	err = pairs("prefer_pair", r.PreferPair, func(crew string, with string) {
+		r.prefer[crew] = append(r.prefer[crew], with)
+	})
+	if err != nil {
+		return err
+	}
+
+	err = pairs("never_pair", r.NeverPair, func(crew string, with string) {
+		for _, pair := range [][2]string{{crew, with}, {with, crew}} {
+			if r.never[pair[0]] == nil {
+				r.never[pair[0]] = make(map[string]bool)
+			}
+			r.never[pair[0]][pair[1]] = true
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	if r.Progression != nil {
+		r.Progression.Instructor = qualificationName(r.Progression.Instructor)
+		if r.Progression.Instructor == "" && len(r.Progression.Crew) > 0 {
+			return fmt.Errorf("progression has crew but no instructor qualification")
+		}
+
+		for i, name := range r.Progression.Crew {
+			crew, err := key(fmt.Sprintf("progression.crew[%d]", i), name)
+			if err != nil {
+				return err
+			}
+			r.progression[crew] = true
+		}
+	}
+
+	for crew, with := range r.must {
+		for _, other := range with {
+			if r.never[crew][other] {
+				return fmt.Errorf("%s must fly with %s, but is never paired with them", r.names[crew], r.names[other])
+			}
+		}
+	}
+
+	return nil
+}
+
+// A warning for every name in the rules that isn't on the roster read from rosterFileName, since a rule for
+// them can't do anything
+func (r *PairingRules) unknownCrew(crew []*CrewAvailability, fileName string, rosterFileName string) []string {
+	var (
+		warnings = []string{}
+		onRoster = make(map[string]bool)
+		keys     = []string{}
+	)
+
+	for _, c := range crew {
+		onRoster[hoursKey(c.FirstName, c.LastName)] = true
+	}
+
+	for key := range r.names {
+		if !onRoster[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		warnings = append(warnings, fmt.Sprintf("%s is in %s but not %s", r.names[key], fileName, rosterFileName))
+	}
+
+	return warnings
+}
+
+// Why the crew member can't fly with the others on a flight, or blank if they can. isInstructor says whether
+// another crew member holds the progression instructor qualification
+func (r *PairingRules) violation(key string, others []string, isInstructor func(key string) bool) string {
+	for _, other := range others {
+		if r.never[key][other] {
+			return fmt.Sprintf("never paired with %s", r.names[other])
+		}
+	}
+
+	if with, ok := r.must[key]; ok && !r.pairedWith(with, others) {
+		return fmt.Sprintf("must fly with %s", r.nameList(with))
+	}
+
+	if r.progression[key] {
+		for _, other := range others {
+			if isInstructor(other) {
+				return ""
+			}
+		}
+		return fmt.Sprintf("in progression, so needs someone %s qualified on the flight", r.Progression.Instructor)
+	}
+
+	return ""
+}
+
+// Names of the crew the crew member prefers to fly with when none of them are among the others, or blank
+func (r *PairingRules) unmetPreference(key string, others []string) string {
+	if with, ok := r.prefer[key]; ok && !r.pairedWith(with, others) {
+		return r.nameList(with)
+	}
+	return ""
+}
+
+func (r *PairingRules) pairedWith(with []string, others []string) bool {
+	for _, partner := range with {
+		for _, other := range others {
+			if partner == other {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (r *PairingRules) nameList(keys []string) string {
+	names := []string{}
+	for _, key := range keys {
+		names = append(names, r.names[key])
+	}
+	return strings.Join(names, " or ")
+}
+
+// Penalty for the flight's unmet preferences, and whether its crew keep every hard pairing rule. Pinned crew
+// can't be moved, so their must_pair and progression rules add to the penalty instead
+func (solver *scheduleSolver) pairingCost(flightIndex int, crewIndexes []int) (int, bool) {
+	var (
+		rules   = solver.pairing
+		flight  = solver.flightSchedules.Flights[flightIndex]
+		keys    = make([]string, len(crewIndexes))
+		penalty = 0
+	)
+
+	for i, crewIndex := range crewIndexes {
+		keys[i] = solver.crewKeys[crewIndex]
+	}
+
+	for i, crewIndex := range crewIndexes {
+		others := append(append([]string{}, keys[:i]...), keys[i+1:]...)
+
+		if rules.unmetPreference(keys[i], others) != "" {
+			penalty += PAIRING_PENALTY
+		}
+
+		reason := rules.violation(keys[i], others, func(key string) bool {
+			other, ok := solver.crewByKey[key]
+			return ok && solver.crew[other].isCurrent(rules.Progression.Instructor, flight.Date)
+		})
+		if reason == "" {
+			continue
+		}
+
+		pinned := false
+		for _, p := range solver.pinned[flightIndex] {
+			pinned = pinned || p == crewIndex
+		}
+		if !pinned || strings.HasPrefix(reason, "never") {
+			return 0, false
+		}
+		penalty += PAIRING_PENALTY
+	}
+
+	return penalty, true
+}
+
+// Keys of everyone seated on the flight, and a check for whether one of them is a progression instructor on
+// the day
+func flightPairingKeys(flightSchedules *FlightSchedules, flight *Flight) ([]string, func(key string) bool) {
+	keys := []string{}
+	for _, crew := range flight.crew() {
+		keys = append(keys, hoursKey(crew.FirstName, crew.LastName))
+	}
+
+	crewByKey := make(map[string]*CrewAvailability)
+	for _, crew := range flightSchedules.Crew {
+		crewByKey[hoursKey(crew.FirstName, crew.LastName)] = crew
+	}
+
+	return keys, func(key string) bool {
+		crew, ok := crewByKey[key]
+		return ok && flightSchedules.Pairing.Progression != nil && crew.isCurrent(flightSchedules.Pairing.Progression.Instructor, flight.Date)
+	}
+}
+
+// Crew seated on the date's flights against a hard pairing rule, or on any day's when date is blank. Like rest,
+// only pins and changes made by hand can do it
+func pairingViolations(flightSchedules *FlightSchedules, date string) []string {
+	violations := []string{}
+	if flightSchedules.Pairing == nil {
+		return violations
+	}
+
+	for _, flight := range flightSchedules.Flights {
+		if date != "" && flight.Date != date {
+			continue
+		}
+
+		keys, isInstructor := flightPairingKeys(flightSchedules, flight)
+		for i, crew := range flight.crew() {
+			others := append(append([]string{}, keys[:i]...), keys[i+1:]...)
+			if reason := flightSchedules.Pairing.violation(keys[i], others, isInstructor); reason != "" {
+				violations = append(violations, fmt.Sprintf("%s on the %s %s flight on %s is %s", crewName(crew.FirstName, crew.LastName), flight.Time, flight.Type, flight.Date, reason))
+			}
+		}
+	}
+
+	return violations
+}
+
+// Every seat taken by crew with a pairing preference and none of the crew they prefer on the flight
+func brokenPreferences(flightSchedules *FlightSchedules) []*brokenPreference {
+	broken := []*brokenPreference{}
+	if flightSchedules.Pairing == nil {
+		return broken
+	}
+
+	for _, flight := range flightSchedules.Flights {
+		keys, _ := flightPairingKeys(flightSchedules, flight)
+		for i, crew := range flight.crew() {
+			others := append(append([]string{}, keys[:i]...), keys[i+1:]...)
+			if prefers := flightSchedules.Pairing.unmetPreference(keys[i], others); prefers != "" {
+				broken = append(broken, &brokenPreference{Flight: flight, Crew: crew, Prefers: prefers})
+			}
+		}
+	}
+
+	return broken
+}
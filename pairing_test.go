@@ -0,0 +1,112 @@
+package main
+
+import "testing"
+
+func TestPairingViolation(t *testing.T) {
+	rules := &PairingRules{
+		MustPair:    []*PairingRule{{Crew: "Doe, Jane", With: []string{"Roe, Rick", "Poe, Sam"}}},
+		PreferPair:  []*PairingRule{{Crew: "Moe, Lee", With: []string{"Roe, Rick"}}},
+		NeverPair:   []*PairingRule{{Crew: "Doe, Jane", With: []string{"Zoe, Kim"}}},
+		Progression: &ProgressionRule{Instructor: "ip", Crew: []string{"Moe, Lee"}},
+	}
+	if err := rules.validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		jane = hoursKey("Jane", "Doe")
+		rick = hoursKey("Rick", "Roe")
+		sam  = hoursKey("Sam", "Poe")
+		kim  = hoursKey("Kim", "Zoe")
+		lee  = hoursKey("Lee", "Moe")
+	)
+	isInstructor := func(key string) bool { return key == rick }
+
+	tests := []struct {
+		name           string
+		crew           string
+		others         []string
+		wantViolation  string
+		wantPreference string
+	}{
+		{name: "must pair with the first partner", crew: jane, others: []string{rick}},
+		{name: "must pair with another partner", crew: jane, others: []string{sam}},
+		{name: "must pair without a partner", crew: jane, others: []string{lee}, wantViolation: "must fly with Roe, Rick or Poe, Sam"},
+		{name: "never paired", crew: jane, others: []string{rick, kim}, wantViolation: "never paired with Zoe, Kim"},
+		{name: "never paired both ways round", crew: kim, others: []string{jane}, wantViolation: "never paired with Doe, Jane"},
+		{name: "progression with an instructor", crew: lee, others: []string{rick}},
+		{name: "progression without an instructor", crew: lee, others: []string{sam}, wantViolation: "in progression, so needs someone IP qualified on the flight", wantPreference: "Roe, Rick"},
+		{name: "no rules", crew: sam, others: []string{kim}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := rules.violation(test.crew, test.others, isInstructor); got != test.wantViolation {
+				t.Errorf("violation = %q, want %q", got, test.wantViolation)
+			}
+			if got := rules.unmetPreference(test.crew, test.others); got != test.wantPreference {
+				t.Errorf("unmetPreference = %q, want %q", got, test.wantPreference)
+			}
+		})
+	}
+}
+
+func TestPairingRulesValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules *PairingRules
+		want  string
+	}{
+		{
+			name:  "name without a comma",
+			rules: &PairingRules{MustPair: []*PairingRule{{Crew: "Jane Doe", With: []string{"Roe, Rick"}}}},
+			want:  `must_pair[0].crew is "Jane Doe"; expected a name like "Doe, Jane"`,
+		},
+		{
+			name:  "nobody to pair with",
+			rules: &PairingRules{PreferPair: []*PairingRule{{Crew: "Doe, Jane"}}},
+			want:  "prefer_pair[0].with names nobody",
+		},
+		{
+			name:  "paired with themselves",
+			rules: &PairingRules{NeverPair: []*PairingRule{{Crew: "Doe, Jane", With: []string{"doe,  jane"}}}},
+			want:  `never_pair[0] pairs doe,  jane with themselves`,
+		},
+		{
+			name:  "progression without an instructor",
+			rules: &PairingRules{Progression: &ProgressionRule{Crew: []string{"Doe, Jane"}}},
+			want:  "progression has crew but no instructor qualification",
+		},
+		{
+			name: "must and never paired",
+			rules: &PairingRules{
+				MustPair:  []*PairingRule{{Crew: "Doe, Jane", With: []string{"Roe, Rick"}}},
+				NeverPair: []*PairingRule{{Crew: "Roe, Rick", With: []string{"Doe, Jane"}}},
+			},
+			want: "Doe, Jane must fly with Roe, Rick, but is never paired with them",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.rules.validate()
+			if err == nil || err.Error() != test.want {
+				t.Errorf("validate = %v, want %q", err, test.want)
+			}
+		})
+	}
+}
+
+func TestPairingUnknownCrew(t *testing.T) {
+	rules := &PairingRules{NeverPair: []*PairingRule{{Crew: "Doe, Jane", With: []string{"Zoe, Kim"}}}}
+	if err := rules.validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	crew := []*CrewAvailability{{FirstName: "Jane", LastName: "Doe"}}
+	got := rules.unknownCrew(crew, "pairings.json", "week.json")
+	want := []string{"Zoe, Kim is in pairings.json but not week.json"}
+	if len(got) != len(want) || got[0] != want[0] {
+		t.Errorf("unknownCrew = %q, want %q", got, want)
+	}
+}
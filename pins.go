@@ -0,0 +1,344 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tealeg/xlsx"
+)
+
+const (
+	PINS_SHEET = "Pins" // Optional sheet in Troop to Task
+
+	PIN_DATE_COL       = 0
+	PIN_TIME_COL       = 1
+	PIN_TYPE_COL       = 2
+	PIN_LAST_NAME_COL  = 3
+	PIN_FIRST_NAME_COL = 4
+)
+
+var pinDateLayouts = []string{
+	INPUT_DATE_FORMAT,
+	"1/2/06",
+	"01-02-06",
+	"2006-01-02",
+	FULL_DATE_FORMAT,
+}
+
+// Crew put on a flight by hand. The solver keeps them there and schedules everyone else around them
+type Pin struct {
+	Date      string // Format: Jan 01 06
+	Time      string
+	Type      string // Optional when the time alone picks out the flight
+	FirstName string
+	LastName  string
+	Source    string // Where the pin came from, e.g. a sheet row or the command line
+	Seat      string // Status of the seat when it isn't the crew member's own, e.g. PI for a PC substituting for one
+
+	flight *Flight // The one flight the pin is for, when there may be others at the same time
+}
+
+func (p *Pin) String() string {
+	flight := p.Time
+	if p.Type != "" {
+		flight = fmt.Sprintf("%s %s", p.Time, p.Type)
+	}
+	return fmt.Sprintf("%s %s on the %s flight on %s", p.FirstName, p.LastName, flight, p.Date)
+}
+
+// Reads the pins sheet, one pin per row under a heading row. Rows that can't be read are returned as problems
+func pinsFromSheet(sheet *xlsx.Sheet, fileName string) ([]*Pin, []error) {
+	var (
+		pins     = []*Pin{}
+		problems = []error{}
+	)
+
+	for r, row := range sheet.Rows {
+		if r == 0 || row == nil {
+			continue
+		}
+
+		values := make([]string, PIN_FIRST_NAME_COL+1)
+		for col := range values {
+			val, err := cellValue(row, col)
+			if err != nil {
+				problems = append(problems, &CellError{File: fileName, Sheet: sheet.Name, Row: r, Col: col, Problem: err.Error()})
+			}
+			values[col] = strings.TrimSpace(val)
+		}
+
+		if strings.Join(values, "") == "" {
+			continue
+		}
+
+		date, ok := parsePinDate(values[PIN_DATE_COL])
+		if !ok {
+			problems = append(problems, &CellError{File: fileName, Sheet: sheet.Name, Row: r, Col: PIN_DATE_COL, Problem: fmt.Sprintf("%q is not a date", values[PIN_DATE_COL])})
+			continue
+		}
+
+		flightTime, ok := parsePinTime(values[PIN_TIME_COL])
+		if !ok {
+			problems = append(problems, &CellError{File: fileName, Sheet: sheet.Name, Row: r, Col: PIN_TIME_COL, Problem: fmt.Sprintf("%q is not a time like 0900", values[PIN_TIME_COL])})
+			continue
+		}
+
+		if values[PIN_LAST_NAME_COL] == "" || values[PIN_FIRST_NAME_COL] == "" {
+			problems = append(problems, &CellError{File: fileName, Sheet: sheet.Name, Row: r, Col: PIN_LAST_NAME_COL, Problem: "crew name is blank"})
+			continue
+		}
+
+		pins = append(pins, &Pin{
+			Date:      date.Format(FULL_DATE_FORMAT),
+			Time:      flightTime,
+			Type:      strings.ToUpper(values[PIN_TYPE_COL]),
+			FirstName: values[PIN_FIRST_NAME_COL],
+			LastName:  values[PIN_LAST_NAME_COL],
+			Source:    fmt.Sprintf("%s, sheet %q, row %d", fileName, sheet.Name, r+1),
+		})
+	}
+
+	return pins, problems
+}
+
+// Pin from the command line, e.g. "1/6/2026 0900 MAINTENANCE=Doe, Jane"; the flight type is optional
+func parsePin(value string) (*Pin, error) {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("expected DATE TIME [TYPE]=LAST, FIRST")
+	}
+
+	flight := strings.Fields(parts[0])
+	if len(flight) < 2 || len(flight) > 3 {
+		return nil, fmt.Errorf("expected DATE TIME [TYPE] before the =")
+	}
+
+	date, ok := parsePinDate(flight[0])
+	if !ok {
+		return nil, fmt.Errorf("%q is not a date like 1/2/2026", flight[0])
+	}
+
+	flightTime, ok := parsePinTime(flight[1])
+	if !ok {
+		return nil, fmt.Errorf("%q is not a time like 0900", flight[1])
+	}
+
+	flightType := ""
+	if len(flight) == 3 {
+		flightType = strings.ToUpper(flight[2])
+		if !isFlightType(flightType) {
+			return nil, fmt.Errorf("unknown flight type %q", flight[2])
+		}
+	}
+
+	name := strings.SplitN(parts[1], ",", 2)
+	if len(name) != 2 || strings.TrimSpace(name[0]) == "" || strings.TrimSpace(name[1]) == "" {
+		return nil, fmt.Errorf("expected a name like \"Doe, Jane\" after the =")
+	}
+
+	return &Pin{
+		Date:      date.Format(FULL_DATE_FORMAT),
+		Time:      flightTime,
+		Type:      flightType,
+		FirstName: strings.TrimSpace(name[1]),
+		LastName:  strings.TrimSpace(name[0]),
+		Source:    "--pin",
+	}, nil
+}
+
+func parsePinDate(val string) (time.Time, bool) {
+	for _, layout := range pinDateLayouts {
+		if date, err := time.Parse(layout, val); err == nil {
+			return date, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// Normalizes 900, 09:00 and 0900 to FLIGHT_TIME_FORMAT
+func parsePinTime(val string) (string, bool) {
+	val = strings.Replace(val, ":", "", 1)
+	if len(val) == 3 {
+		val = "0" + val
+	}
+
+	t, err := time.Parse(FLIGHT_TIME_FORMAT, val)
+	if err != nil {
+		return "", false
+	}
+	return t.Format(FLIGHT_TIME_FORMAT), true
+}
+
+// Key: flight index; Value: indexes of the crew pinned to it. Also returns the seats of crew pinned as substitutes,
+// keyed by flight index and then crew index. Every pin that can't be kept is reported
+func resolvePins(s *SchedulePayload, flightSchedules *FlightSchedules) (map[int][]int, map[int]map[int]string, error) {
+	var (
+		pinned        = make(map[int][]int)
+		pinnedSeats   = make(map[int]map[int]string)
+		problems      = []error{}
+		crewByName    = make(map[string]int)
+		pinsByFlight  = make(map[int][]*Pin)
+		flightsByDate = make(map[string][]int)
+		pinnedFlights = make(map[int][]*Flight) // Key: crew index
+	)
+
+	for i, crew := range s.CrewAvailability {
+		crewByName[hoursKey(crew.FirstName, crew.LastName)] = i
+	}
+
+	for i, flight := range flightSchedules.Flights {
+		flightsByDate[flight.Date] = append(flightsByDate[flight.Date], i)
+	}
+
+	for _, pin := range s.Pins {
+		crewIndex, ok := crewByName[hoursKey(pin.FirstName, pin.LastName)]
+		if !ok {
+			problems = append(problems, &PinError{Pin: pin, Problem: "not in Troop to Task"})
+			continue
+		}
+		crew := s.CrewAvailability[crewIndex]
+
+		if available, ok := crew.Availabilty[pin.Date]; !ok {
+			problems = append(problems, &PinError{Pin: pin, Problem: "not listed for that day in Troop to Task"})
+			continue
+		} else if !available {
+			problems = append(problems, &PinError{Pin: pin, Problem: fmt.Sprintf("unavailable (code %s) in Troop to Task", crew.Codes[pin.Date])})
+			continue
+		}
+
+		seat := crew.Status
+		if pin.Seat != "" && pin.Seat != crew.Status {
+			if _, ok := flightConfig.Substitutions.penalty(pin.Seat, crew.Status); !ok {
+				problems = append(problems, &PinError{Pin: pin, Problem: fmt.Sprintf("a %s can't substitute in a %s seat", crew.Status, pin.Seat)})
+				continue
+			}
+			seat = pin.Seat
+		}
+
+		matched, flightIndex, unavailable := false, -1, ""
+		for _, i := range flightsByDate[pin.Date] {
+			flight := flightSchedules.Flights[i]
+			if flight.Time != pin.Time || (pin.Type != "" && flight.Type != pin.Type) || (pin.flight != nil && flight != pin.flight) {
+				continue
+			}
+			matched = true
+
+			if reason := crew.unavailableReason(flight); reason != "" {
+				unavailable = reason
+				continue
+			}
+			if reason := crew.unqualifiedReasonFor(flight, seat); reason != "" {
+				unavailable = reason
+				continue
+			}
+
+			if pinnedOfStatus(s.CrewAvailability, pinned[i], pinnedSeats[i], seat) < flight.crewRule().maxSeats(seat) {
+				flightIndex = i
+				break
+			}
+		}
+
+		if !matched {
+			problems = append(problems, &PinError{Pin: pin, Problem: "no such flight is being scheduled"})
+			continue
+		}
+		if flightIndex < 0 && unavailable != "" {
+			problems = append(problems, &PinError{Pin: pin, Problem: unavailable})
+			continue
+		}
+		if flightIndex < 0 {
+			problems = append(problems, &PinError{Pin: pin, Problem: fmt.Sprintf("the flight has no room for another %s", seat)})
+			continue
+		}
+
+		flight := flightSchedules.Flights[flightIndex]
+		pinnedToday := []*Flight{}
+		for _, other := range pinnedFlights[crewIndex] {
+			if other.Date == flight.Date {
+				pinnedToday = append(pinnedToday, other)
+			}
+		}
+		policy := flightConfig.dutyDayPolicy(crew.FirstName, crew.LastName, crew.Status)
+		if reason := policy.violation(pinnedToday, flight); reason != "" {
+			problems = append(problems, &PinError{Pin: pin, Problem: reason})
+			continue
+		}
+		if reason := flightConfig.Rest.violation(pinnedFlights[crewIndex], flight); reason != "" {
+			problems = append(problems, &PinError{Pin: pin, Problem: reason})
+			continue
+		}
+		if neverPaired := pinnedNeverPaired(s, crewIndex, pinned[flightIndex]); neverPaired != "" {
+			problems = append(problems, &PinError{Pin: pin, Problem: fmt.Sprintf("never paired with %s, who is pinned to the flight", neverPaired)})
+			continue
+		}
+
+		pinned[flightIndex] = append(pinned[flightIndex], crewIndex)
+		if seat != crew.Status {
+			if pinnedSeats[flightIndex] == nil {
+				pinnedSeats[flightIndex] = make(map[int]string)
+			}
+			pinnedSeats[flightIndex][crewIndex] = seat
+		}
+		pinnedFlights[crewIndex] = append(pinnedFlights[crewIndex], flight)
+		pinsByFlight[flightIndex] = append(pinsByFlight[flightIndex], pin)
+	}
+
+	for flightIndex, flight := range flightSchedules.Flights {
+		crewIndexes, ok := pinned[flightIndex]
+		if ok && len(pinnedCompositions(s.CrewAvailability, flight, crewIndexes, pinnedSeats[flightIndex])) == 0 {
+			pins := pinsByFlight[flightIndex]
+			problems = append(problems, &PinError{Pin: pins[len(pins)-1], Problem: fmt.Sprintf("no crew composition of %s flights takes all the crew pinned to it", flight.Type)})
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, nil, &ValidationError{Problems: problems}
+	}
+
+	return pinned, pinnedSeats, nil
+}
+
+// Name of crew already pinned to the flight that the pairing rules never put with the crew member, or blank
+func pinnedNeverPaired(s *SchedulePayload, crewIndex int, crewIndexes []int) string {
+	if s.Pairing == nil {
+		return ""
+	}
+
+	crew := s.CrewAvailability[crewIndex]
+	for _, other := range crewIndexes {
+		otherCrew := s.CrewAvailability[other]
+		if s.Pairing.never[hoursKey(crew.FirstName, crew.LastName)][hoursKey(otherCrew.FirstName, otherCrew.LastName)] {
+			return crewName(otherCrew.FirstName, otherCrew.LastName)
+		}
+	}
+	return ""
+}
+
+// Status of the seat a pinned crew member takes: their own, unless seats has them substituting
+func pinnedSeat(crew []*CrewAvailability, crewIndex int, seats map[int]string) string {
+	if seat, ok := seats[crewIndex]; ok {
+		return seat
+	}
+	return crew[crewIndex].Status
+}
+
+func pinnedOfStatus(crew []*CrewAvailability, crewIndexes []int, seats map[int]string, status string) int {
+	n := 0
+	for _, crewIndex := range crewIndexes {
+		if pinnedSeat(crew, crewIndex, seats) == status {
+			n++
+		}
+	}
+	return n
+}
+
+// Indexes of the flight's compositions with room for every pinned crew member
+func pinnedCompositions(crew []*CrewAvailability, flight *Flight, crewIndexes []int, seats map[int]string) []int {
+	seated := make(map[string]int)
+	for _, status := range seatStatuses {
+		seated[status] = pinnedOfStatus(crew, crewIndexes, seats, status)
+	}
+
+	return flight.crewRule().compositionsFitting(seated)
+}
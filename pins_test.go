@@ -0,0 +1,172 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const pinsDate = "Jan 05 26"
+
+// PC0, PC1, PI0 and PI1 Doe, all available on pinsDate
+func pinsCrew() []*CrewAvailability {
+	crew := []*CrewAvailability{}
+	for _, status := range []string{"PC", "PI"} {
+		for i := 0; i < 2; i++ {
+			crew = append(crew, &CrewAvailability{
+				FirstName:   fmt.Sprintf("%s%d", status, i),
+				LastName:    "Doe",
+				Rank:        "CW2",
+				Status:      status,
+				Availabilty: map[string]bool{pinsDate: true},
+				Codes:       map[string]string{pinsDate: ""},
+			})
+		}
+	}
+	return crew
+}
+
+// Maintenance flights at 0800 and 1100 on pinsDate
+func pinsFlights() *FlightSchedules {
+	return &FlightSchedules{Flights: []*Flight{
+		{Type: "MAINTENANCE", Date: pinsDate, Time: "0800", Duration: 2 * time.Hour},
+		{Type: "MAINTENANCE", Date: pinsDate, Time: "1100", Duration: 2 * time.Hour},
+	}}
+}
+
+func TestResolvePins(t *testing.T) {
+	testPin := func(firstName string, flightTime string) *Pin {
+		return &Pin{Date: pinsDate, Time: flightTime, FirstName: firstName, LastName: "Doe", Source: "--pin"}
+	}
+
+	substitute := testPin("PC1", "0800")
+	substitute.Seat = "PI"
+
+	tests := []struct {
+		name          string
+		pins          []*Pin
+		unavailable   []string // First names of crew out on pinsDate
+		neverPair     [][2]string
+		substitutions Substitutions
+		wantPinned    map[int][]int
+		wantSeats     map[int]map[int]string
+		wantProblems  []string
+	}{
+		{
+			name:       "pins that fit",
+			pins:       []*Pin{testPin("PC1", "0800"), testPin("PI0", "1100")},
+			wantPinned: map[int][]int{0: {1}, 1: {2}},
+		},
+		{
+			name:         "unavailable crew",
+			pins:         []*Pin{testPin("PC0", "0800")},
+			unavailable:  []string{"PC0"},
+			wantProblems: []string{"unavailable (code S) in Troop to Task"},
+		},
+		{
+			name:         "no room on the flight",
+			pins:         []*Pin{testPin("PC0", "0800"), testPin("PC1", "0800")},
+			wantProblems: []string{"the flight has no room for another PC"},
+		},
+		{
+			name:          "substitute pinned to another status's seat",
+			pins:          []*Pin{testPin("PC0", "0800"), substitute},
+			substitutions: Substitutions{"PI": {"PC": 30}},
+			wantPinned:    map[int][]int{0: {0, 1}},
+			wantSeats:     map[int]map[int]string{0: {1: "PI"}},
+		},
+		{
+			name:         "substitute the substitutions don't allow",
+			pins:         []*Pin{testPin("PC0", "0800"), substitute},
+			wantProblems: []string{"a PC can't substitute in a PI seat"},
+		},
+		{
+			name:         "never paired with crew already pinned",
+			pins:         []*Pin{testPin("PC0", "0800"), testPin("PI0", "0800")},
+			neverPair:    [][2]string{{"Doe, PC0", "Doe, PI0"}},
+			wantProblems: []string{"never paired with PC0 Doe, who is pinned to the flight"},
+		},
+		{
+			name: "every problem reported together",
+			pins: []*Pin{
+				testPin("PC0", "0800"),
+				testPin("PC1", "0800"),
+				testPin("PI0", "0800"),
+				testPin("Nobody", "0800"),
+				testPin("PI1", "1400"),
+			},
+			unavailable: []string{"PC0"},
+			wantProblems: []string{
+				"unavailable (code S) in Troop to Task",
+				"not in Troop to Task",
+				"no such flight is being scheduled",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			crew := pinsCrew()
+			for _, c := range crew {
+				for _, firstName := range test.unavailable {
+					if c.FirstName == firstName {
+						c.Availabilty[pinsDate] = false
+						c.Codes[pinsDate] = "S"
+					}
+				}
+			}
+
+			s := NewSchedulePayload(crew)
+			s.Pins = test.pins
+			if len(test.neverPair) > 0 {
+				s.Pairing = &PairingRules{}
+				for _, pair := range test.neverPair {
+					s.Pairing.NeverPair = append(s.Pairing.NeverPair, &PairingRule{Crew: pair[0], With: []string{pair[1]}})
+				}
+				if err := s.Pairing.validate(); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			config := *flightConfig
+			config.Substitutions = test.substitutions
+			saved := flightConfig
+			flightConfig = &config
+			defer func() { flightConfig = saved }()
+
+			pinned, seats, err := resolvePins(s, pinsFlights())
+
+			problems := []string{}
+			var validationErr *ValidationError
+			if errors.As(err, &validationErr) {
+				for _, problem := range validationErr.Problems {
+					var pinErr *PinError
+					if !errors.As(problem, &pinErr) {
+						t.Fatalf("problem %v isn't a PinError", problem)
+					}
+					problems = append(problems, pinErr.Problem)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(test.wantProblems) == 0 {
+				test.wantProblems = []string{}
+				if !reflect.DeepEqual(pinned, test.wantPinned) {
+					t.Errorf("pinned = %v, want %v", pinned, test.wantPinned)
+				}
+				if test.wantSeats == nil {
+					test.wantSeats = map[int]map[int]string{}
+				}
+				if !reflect.DeepEqual(seats, test.wantSeats) {
+					t.Errorf("seats = %v, want %v", seats, test.wantSeats)
+				}
+			}
+			if !reflect.DeepEqual(problems, test.wantProblems) {
+				t.Errorf("problems = %q, want %q", problems, test.wantProblems)
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tealeg/xlsx"
+)
+
+const (
+	QUALIFICATIONS_SHEET = "Qualifications" // Optional sheet in info.xlsx
+
+	QUALIFICATION_NAME_COL    = 0 // "Last, First", as on the hours sheet
+	QUALIFICATION_COL         = 1 // e.g. NVG, IP, MTP or INSTRUMENT
+	QUALIFICATION_EXPIRES_COL = 2 // Last day the qualification is current; blank if it doesn't lapse
+)
+
+// Key: hours key of the crew member; Value: expiry of each of their qualifications, zero if it doesn't lapse
+type Qualifications map[string]map[string]time.Time
+
+func qualificationName(name string) string {
+	return strings.ToUpper(strings.TrimSpace(name))
+}
+
+// Reads the qualifications sheet, one qualification per row under a heading row, e.g.
+//
+//	Name       | Qualification | Expires
+//	Doe, Jane  | MTP           |
+//	Doe, Jane  | INSTRUMENT    | 3/31/2026
+//
+// Rows that can't be read are returned as problems
+func qualificationsFromSheet(sheet *xlsx.Sheet, fileName string) (Qualifications, []error) {
+	var (
+		qualifications = make(Qualifications)
+		problems       = []error{}
+	)
+
+	for r, row := range sheet.Rows {
+		if r == 0 || row == nil {
+			continue
+		}
+
+		values := make([]string, QUALIFICATION_EXPIRES_COL+1)
+		for col := range values {
+			val, err := cellValue(row, col)
+			if err != nil {
+				problems = append(problems, &CellError{File: fileName, Sheet: sheet.Name, Row: r, Col: col, Problem: err.Error()})
+			}
+			values[col] = strings.TrimSpace(val)
+		}
+
+		if strings.Join(values, "") == "" {
+			continue
+		}
+
+		name := strings.SplitN(values[QUALIFICATION_NAME_COL], ",", 2)
+		if len(name) != 2 || strings.TrimSpace(name[0]) == "" || strings.TrimSpace(name[1]) == "" {
+			problems = append(problems, &CellError{File: fileName, Sheet: sheet.Name, Row: r, Col: QUALIFICATION_NAME_COL, Problem: fmt.Sprintf("%q is not a name like \"Doe, Jane\"", values[QUALIFICATION_NAME_COL])})
+			continue
+		}
+
+		qualification := qualificationName(values[QUALIFICATION_COL])
+		if qualification == "" {
+			problems = append(problems, &CellError{File: fileName, Sheet: sheet.Name, Row: r, Col: QUALIFICATION_COL, Problem: "qualification is blank"})
+			continue
+		}
+
+		var expires time.Time
+		if values[QUALIFICATION_EXPIRES_COL] != "" {
+			date, ok := parsePinDate(values[QUALIFICATION_EXPIRES_COL])
+			if !ok {
+				problems = append(problems, &CellError{File: fileName, Sheet: sheet.Name, Row: r, Col: QUALIFICATION_EXPIRES_COL, Problem: fmt.Sprintf("%q is not a date", values[QUALIFICATION_EXPIRES_COL])})
+				continue
+			}
+			expires = date
+		}
+
+		qualifications.add(hoursKey(name[1], name[0]), qualification, expires)
+	}
+
+	return qualifications, problems
+}
+
+func (q Qualifications) add(key string, qualification string, expires time.Time) {
+	if q[key] == nil {
+		q[key] = make(map[string]time.Time)
+	}
+	q[key][qualification] = expires
+}
+
+// Why the crew member can't take a seat of their status on the flight, or blank if they hold every
+// qualification it needs and each is current on the day
+func (crew *CrewAvailability) unqualifiedReason(flight *Flight) string {
+	return crew.unqualifiedReasonFor(flight, crew.Status)
+}
+
+// Same as unqualifiedReason, for a seat of the given status
+func (crew *CrewAvailability) unqualifiedReasonFor(flight *Flight, status string) string {
+	for _, qualification := range flight.Requires[status] {
+		expires, ok := crew.Qualifications[qualification]
+		if !ok {
+			return fmt.Sprintf("not %s qualified", qualification)
+		}
+		if expires.IsZero() {
+			continue
+		}
+
+		date, err := time.Parse(FULL_DATE_FORMAT, flight.Date)
+		if err == nil && date.After(expires) {
+			return fmt.Sprintf("%s expired %s", qualification, expires.Format(FULL_DATE_FORMAT))
+		}
+	}
+	return ""
+}
+
+// Whether the crew member holds the qualification and it hasn't lapsed by the date
+func (crew *CrewAvailability) isCurrent(qualification string, date string) bool {
+	expires, ok := crew.Qualifications[qualification]
+	if !ok || expires.IsZero() {
+		return ok
+	}
+
+	day, err := time.Parse(FULL_DATE_FORMAT, date)
+	return err != nil || !day.After(expires)
+}
+
+func (crew *CrewAvailability) isQualified(flight *Flight) bool {
+	return crew.isQualifiedFor(flight, crew.Status)
+}
+
+func (crew *CrewAvailability) isQualifiedFor(flight *Flight, status string) bool {
+	return len(flight.Requires[status]) == 0 || crew.unqualifiedReasonFor(flight, status) == ""
+}
+
+// A warning for every qualification that lapses part way through the schedule
+func expiringQualifications(crew []*CrewAvailability, flightSchedules *FlightSchedules) []string {
+	var (
+		warnings    = []string{}
+		first, last time.Time
+	)
+
+	for _, flight := range flightSchedules.Flights {
+		date, err := time.Parse(FULL_DATE_FORMAT, flight.Date)
+		if err != nil {
+			continue
+		}
+		if first.IsZero() || date.Before(first) {
+			first = date
+		}
+		if date.After(last) {
+			last = date
+		}
+	}
+
+	for _, c := range crew {
+		names := []string{}
+		for qualification := range c.Qualifications {
+			names = append(names, qualification)
+		}
+		sort.Strings(names)
+
+		for _, qualification := range names {
+			expires := c.Qualifications[qualification]
+			if !expires.IsZero() && !expires.Before(first) && expires.Before(last) {
+				warnings = append(warnings, fmt.Sprintf("%s, %s's %s expires %s, part way through the schedule", c.LastName, c.FirstName, qualification, expires.Format(FULL_DATE_FORMAT)))
+			}
+		}
+	}
+
+	return warnings
+}
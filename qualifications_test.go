@@ -0,0 +1,74 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestQualificationLapsesAfterItsExpiry(t *testing.T) {
+	crew := &CrewAvailability{
+		FirstName: "Jane",
+		LastName:  "Doe",
+		Status:    "PC",
+		Qualifications: map[string]time.Time{
+			"MTP": time.Date(2026, time.March, 3, 0, 0, 0, 0, time.UTC),
+			"NVG": {},
+		},
+	}
+
+	tests := []struct {
+		date    string
+		current bool
+		reason  string
+	}{
+		{date: "Mar 02 26", current: true},
+		{date: "Mar 03 26", current: true}, // Expiry is the last day it's current
+		{date: "Mar 04 26", current: false, reason: "MTP expired Mar 03 26"},
+	}
+	for _, test := range tests {
+		flight := &Flight{Type: "MAINTENANCE", Date: test.date, Time: "0900", Requires: map[string][]string{"PC": {"NVG", "MTP"}}}
+
+		if got := crew.isCurrent("MTP", test.date); got != test.current {
+			t.Errorf("isCurrent(MTP, %s) = %v, want %v", test.date, got, test.current)
+		}
+		if got := crew.unqualifiedReason(flight); got != test.reason {
+			t.Errorf("unqualifiedReason on %s = %q, want %q", test.date, got, test.reason)
+		}
+	}
+
+	if !crew.isCurrent("NVG", "Dec 31 99") {
+		t.Error("NVG without an expiry lapsed")
+	}
+	if crew.isCurrent("IP", "Mar 02 26") {
+		t.Error("IP is current without being held")
+	}
+}
+
+func TestExpiringQualificationsWarnsPartWayThrough(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, time.March, d, 0, 0, 0, 0, time.UTC) }
+	crew := []*CrewAvailability{{
+		FirstName: "Jane",
+		LastName:  "Doe",
+		Qualifications: map[string]time.Time{
+			"LAPSED":  day(1), // Before the schedule, so flights already can't use it
+			"FIRST":   day(2), // Current every day until the first day's flights are done
+			"MIDWEEK": day(4),
+			"LAST":    day(6), // Current on every day of the schedule
+			"NVG":     {},
+		},
+	}}
+	flightSchedules := &FlightSchedules{Flights: []*Flight{
+		{Type: "MAINTENANCE", Date: "Mar 06 26", Time: "0900"},
+		{Type: "MAINTENANCE", Date: "Mar 02 26", Time: "0900"},
+	}}
+
+	got := expiringQualifications(crew, flightSchedules)
+	want := []string{
+		"Doe, Jane's FIRST expires Mar 02 26, part way through the schedule",
+		"Doe, Jane's MIDWEEK expires Mar 04 26, part way through the schedule",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expiringQualifications = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tealeg/xlsx"
+)
+
+const (
+	CHANGE_ADDED   = "added"
+	CHANGE_REMOVED = "removed"
+	CHANGE_MOVED   = "moved"
+)
+
+var changesSheetHeading = []string{
+	"Date",
+	"Flight Type",
+	"Time",
+	"Change",
+	"Status",
+	"Rank",
+	"First Name",
+	"Last Name",
+	"Details",
+}
+
+// A seat that's different from the previous schedule
+type Change struct {
+	Flight  *Flight
+	Crew    *CrewMember
+	Kind    string // CHANGE_ADDED, CHANGE_REMOVED or CHANGE_MOVED
+	Details string // Why crew were removed, or the flight moved crew came from
+}
+
+func (c *Change) String() string {
+	s := fmt.Sprintf("%s %s %s: %s %s %s %s %s", c.Flight.Date, c.Flight.Time, c.Flight.Type, c.Kind, c.Crew.Status, c.Crew.Rank, c.Crew.FirstName, c.Crew.LastName)
+	if c.Details != "" {
+		s += " (" + c.Details + ")"
+	}
+	return s
+}
+
+// Re-crews a previously exported schedule against updated availability. Days before today are left alone; on
+// the other days every seat is solved again, changing as few seats as possible without leaving more of them
+// unfilled, so the previous crew keep their seats unless moving them fills more seats. Only pins given for this
+// run are hard
+func replanSchedule(options *GenerateOptions, previousFileName string, today time.Time) (*SchedulePayload, *FlightSchedules, error) {
+	previous, err := readFlightSchedules(previousFileName)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(previous.Flights) == 0 {
+		return nil, nil, fmt.Errorf("%s has no flights to re-plan", previousFileName)
+	}
+
+	dates = []string{}
+	numFlightsByDate = make(map[string]int)
+	frozen := make(map[string]bool)
+
+	for _, day := range flightIndexesByDate(previous) {
+		date, err := time.Parse(FULL_DATE_FORMAT, previous.Flights[day[0]].Date)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		addPlanningDate(date, 0)
+		frozen[previous.Flights[day[0]].Date] = calendarDay(date).Before(calendarDay(today))
+	}
+
+	schedulePayload, err := readSchedulePayload(options, frozen)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		flightSchedules = &FlightSchedules{Flights: []*Flight{}}
+		pins            = []*Pin{}
+		removed         = make(map[*CrewMember]string) // Key: crew on the previous schedule; Value: why they lost their seat
+		crewByName      = make(map[string]*CrewAvailability)
+	)
+
+	for _, crew := range schedulePayload.CrewAvailability {
+		crewByName[hoursKey(crew.FirstName, crew.LastName)] = crew
+	}
+
+	// Days already flown can't change
+	for _, pin := range schedulePayload.Pins {
+		if frozen[pin.Date] {
+			schedulePayload.Warnings = append(schedulePayload.Warnings, fmt.Sprintf("%s: ignoring the pin of %s, since the day is before the re-plan", pin.Source, pin))
+			continue
+		}
+		pins = append(pins, pin)
+	}
+	schedulePayload.Pins = pins
+	schedulePayload.Previous = make(map[*Flight][]string)
+
+	for _, flight := range previous.Flights {
+		replanned := flight.withoutCrew()
+		flightSchedules.Flights = append(flightSchedules.Flights, replanned)
+
+		if frozen[flight.Date] {
+			for _, crew := range flight.crew() {
+				seatCrew(replanned, crew)
+			}
+			replanned.Seats, replanned.MaxSeats = flight.Seats, flight.MaxSeats
+			continue
+		}
+
+		schedulePayload.Previous[replanned] = []string{}
+		for _, crew := range flight.crew() {
+			if reason := replanRemovalReason(crewByName[hoursKey(crew.FirstName, crew.LastName)], crew, flight); reason != "" {
+				removed[crew] = reason
+				continue
+			}
+			schedulePayload.Previous[replanned] = append(schedulePayload.Previous[replanned], hoursKey(crew.FirstName, crew.LastName))
+		}
+	}
+
+	err = schedulePayload.solveFlightSchedules(flightSchedules, frozen)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	flightSchedules.Changes = crewChanges(previous, flightSchedules, frozen, removed)
+	for _, change := range flightSchedules.Changes {
+		if change.Kind == CHANGE_REMOVED && change.Details == "" {
+			change.Details = "seat given to other crew so more of the day's seats could be filled"
+		}
+	}
+
+	return schedulePayload, flightSchedules, nil
+}
+
+// Why crew on the previous schedule can't keep their seat, or blank if they can
+func replanRemovalReason(availability *CrewAvailability, crew *CrewMember, flight *Flight) string {
+	if availability == nil {
+		return "no longer in Troop to Task"
+	}
+	if availability.Status != crew.Status {
+		return fmt.Sprintf("now listed as a %s in Troop to Task", availability.Status)
+	}
+
+	if reason := availability.unavailableReason(flight); reason != "" {
+		return reason
+	}
+	return availability.unqualifiedReasonFor(flight, crew.seatStatus())
+}
+
+func addChangesSheet(file *xlsx.File, flightSchedules *FlightSchedules) error {
+	sheet, err := file.AddSheet("Changes")
+	if err != nil {
+		return err
+	}
+
+	addSheetHeading(sheet, changesSheetHeading)
+	for _, change := range flightSchedules.Changes {
+		row := sheet.AddRow()
+		for _, value := range []string{change.Flight.Date, change.Flight.Type, change.Flight.Time, change.Kind, change.Crew.Status, change.Crew.Rank, change.Crew.FirstName, change.Crew.LastName, change.Details} {
+			cell := row.AddCell()
+			cell.Value = value
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+const replanDate = "Feb 02 26"
+
+func TestSolveFlightSchedulesReplanChangesOnlyTheLostSeat(t *testing.T) {
+	crew := []*CrewAvailability{}
+	for _, name := range []string{"Able", "Baker", "Cole", "Dunn"} {
+		crew = append(crew, &CrewAvailability{
+			FirstName:   "Pat",
+			LastName:    name,
+			Rank:        "CW2",
+			Status:      "PI",
+			Availabilty: map[string]bool{replanDate: true},
+			Codes:       map[string]string{replanDate: ""},
+		})
+	}
+	crew[2].Availabilty[replanDate] = false
+	crew[2].Codes[replanDate] = "L"
+
+	rule := &CrewRule{Seats: map[string]SeatRange{"PI": {Min: 1, Max: 1}}}
+	flights := []*Flight{
+		{Type: "MAINTENANCE", Date: replanDate, Time: "0800", Duration: 2 * time.Hour, Rule: rule},
+		{Type: "MAINTENANCE", Date: replanDate, Time: "1300", Duration: 2 * time.Hour, Rule: rule},
+	}
+
+	// Able and Baker cost less than Dunn, but Dunn's seat doesn't need to change
+	s := NewSchedulePayload(crew)
+	s.Previous = map[*Flight][]string{
+		flights[0]: {hoursKey("Pat", "Cole")},
+		flights[1]: {hoursKey("Pat", "Dunn")},
+	}
+	if err := s.solveFlightSchedules(&FlightSchedules{Flights: flights}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	got := []string{}
+	for _, flight := range flights {
+		for _, c := range flight.crew() {
+			got = append(got, c.LastName)
+		}
+	}
+	if want := []string{"Able", "Dunn"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("crew = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+const DEFAULT_REST_HOURS = 12
+
+/*
+Rest crew need between flights on different days, under "rest" in the flight config, e.g.
+
+	"rest": {"min_hours": 12, "max_consecutive_days": 5}
+
+"min_hours" is the time from the end of one flight to the start of the next, so with 12 hours a 1700 normal
+flight that lands at 2000 can be followed by an 0800 sim the next morning but not an 0700 one.
+"max_consecutive_days" caps how many days in a row anyone flies. Either is turned off with 0. Anything left
+out keeps its default, so without "rest" or "min_hours" crew need 12 hours between flights, and without
+"max_consecutive_days" they can fly any number of days in a row.
+
+Days are crewed one at a time, earliest first, so a flight can take the only crew member who could fly one the
+next day. When that leaves a seat empty the schedule is solved again with them kept off the earlier flight, a
+few times at most, rather than searching every day at once.
+*/
+type RestPolicy struct {
+	MinHours           float64 `json:"min_hours"`
+	MaxConsecutiveDays int     `json:"max_consecutive_days"`
+}
+
+func defaultRestPolicy() *RestPolicy {
+	return &RestPolicy{MinHours: DEFAULT_REST_HOURS}
+}
+
+// Starts from the default policy, so fields missing from the config aren't taken as 0
+func (r *RestPolicy) UnmarshalJSON(data []byte) error {
+	type restPolicy RestPolicy // Without this method, so decoding into it doesn't recurse
+	policy := restPolicy(*defaultRestPolicy())
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return err
+	}
+
+	*r = RestPolicy(policy)
+	return nil
+}
+
+func (r *RestPolicy) validate() error {
+	if r.MinHours < 0 {
+		return fmt.Errorf("rest.min_hours is negative")
+	}
+	if r.MaxConsecutiveDays < 0 {
+		return fmt.Errorf("rest.max_consecutive_days is negative")
+	}
+	return nil
+}
+
+func (r *RestPolicy) minRest() time.Duration {
+	return time.Duration(r.MinHours * float64(time.Hour))
+}
+
+func flightStart(flight *Flight) (time.Time, error) {
+	return time.Parse(FULL_DATE_FORMAT+" "+FLIGHT_TIME_FORMAT, flight.Date+" "+flight.Time)
+}
+
+// Why flying the flight would break the rest policy for crew already on the given flights, or blank if it
+// wouldn't. Flights on the flight's own day are left to the duty day policy
+func (r *RestPolicy) violation(duties []*Flight, flight *Flight) string {
+	start, err := flightStart(flight)
+	if err != nil {
+		return ""
+	}
+	end := start.Add(flight.Duration)
+
+	if r.MinHours > 0 {
+		for _, duty := range duties {
+			if duty.Date == flight.Date {
+				continue
+			}
+
+			dutyStart, err := flightStart(duty)
+			if err != nil {
+				continue
+			}
+			dutyEnd := dutyStart.Add(duty.Duration)
+
+			if dutyStart.Before(start) && start.Sub(dutyEnd) < r.minRest() {
+				return fmt.Sprintf("needs %gh rest after the %s %s flight on %s", r.MinHours, duty.Time, duty.Type, duty.Date)
+			}
+			if start.Before(dutyStart) && dutyStart.Sub(end) < r.minRest() {
+				return fmt.Sprintf("needs %gh rest before the %s %s flight on %s", r.MinHours, duty.Time, duty.Type, duty.Date)
+			}
+		}
+	}
+
+	if r.MaxConsecutiveDays > 0 {
+		if days := consecutiveDays(duties, flight); days > r.MaxConsecutiveDays {
+			return fmt.Sprintf("would fly %d days in a row; at most %d", days, r.MaxConsecutiveDays)
+		}
+	}
+
+	return ""
+}
+
+// Length of the run of flying days that includes the flight's day
+func consecutiveDays(duties []*Flight, flight *Flight) int {
+	date, err := time.Parse(FULL_DATE_FORMAT, flight.Date)
+	if err != nil {
+		return 1
+	}
+
+	flying := make(map[string]bool)
+	for _, duty := range duties {
+		flying[duty.Date] = true
+	}
+
+	days := 1
+	for d := date.AddDate(0, 0, -1); flying[d.Format(FULL_DATE_FORMAT)]; d = d.AddDate(0, 0, -1) {
+		days++
+	}
+	for d := date.AddDate(0, 0, 1); flying[d.Format(FULL_DATE_FORMAT)]; d = d.AddDate(0, 0, 1) {
+		days++
+	}
+
+	return days
+}
+
+// Crew seated on the date's flights in breach of the rest policy, or on any day's when date is blank. The solver
+// never seats anyone that way on its own, but pins, frozen days and changes made by hand can
+func restViolations(flightSchedules *FlightSchedules, date string) []string {
+	var (
+		violations    = []string{}
+		names         = []string{}
+		flightsByCrew = make(map[string][]*Flight)
+	)
+
+	for _, flight := range flightSchedules.Flights {
+		for _, crew := range flight.crew() {
+			name := crewName(crew.FirstName, crew.LastName)
+			if _, ok := flightsByCrew[name]; !ok {
+				names = append(names, name)
+			}
+			flightsByCrew[name] = append(flightsByCrew[name], flight)
+		}
+	}
+
+	sort.Strings(names)
+	for _, name := range names {
+		flights := flightsByCrew[name]
+		for i, flight := range flights {
+			if date != "" && flight.Date != date {
+				continue
+			}
+
+			// Each pair is reported once, from the later flight
+			if reason := flightConfig.Rest.violation(flights[:i], flight); reason != "" {
+				violations = append(violations, fmt.Sprintf("%s on the %s %s flight on %s %s", name, flight.Time, flight.Type, flight.Date, reason))
+			}
+		}
+	}
+
+	return violations
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRestPolicyDefaults(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   RestPolicy
+	}{
+		{name: "empty", config: `{}`, want: RestPolicy{MinHours: DEFAULT_REST_HOURS}},
+		{name: "only max_consecutive_days", config: `{"max_consecutive_days": 5}`, want: RestPolicy{MinHours: DEFAULT_REST_HOURS, MaxConsecutiveDays: 5}},
+		{name: "only min_hours", config: `{"min_hours": 10}`, want: RestPolicy{MinHours: 10}},
+		{name: "min_hours turned off", config: `{"min_hours": 0}`, want: RestPolicy{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var policy RestPolicy
+			if err := json.Unmarshal([]byte(test.config), &policy); err != nil {
+				t.Fatal(err)
+			}
+			if policy != test.want {
+				t.Errorf("policy = %+v, want %+v", policy, test.want)
+			}
+		})
+	}
+}
+
+func TestRestViolation(t *testing.T) {
+	testFlight := func(date string, flightTime string, hours float64) *Flight {
+		return &Flight{Type: "NORMAL", Date: date, Time: flightTime, Duration: time.Duration(hours * float64(time.Hour))}
+	}
+
+	tests := []struct {
+		name   string
+		policy *RestPolicy
+		duties []*Flight
+		flight *Flight
+		want   string
+	}{
+		{
+			name:   "no earlier flights",
+			policy: defaultRestPolicy(),
+			flight: testFlight("Jan 06 26", "0800", 2),
+		},
+		{
+			name:   "exactly min_hours after landing",
+			policy: defaultRestPolicy(),
+			duties: []*Flight{testFlight("Jan 05 26", "1700", 3)},
+			flight: testFlight("Jan 06 26", "0800", 2),
+		},
+		{
+			name:   "less than min_hours after landing",
+			policy: defaultRestPolicy(),
+			duties: []*Flight{testFlight("Jan 05 26", "1700", 3)},
+			flight: testFlight("Jan 06 26", "0700", 2),
+			want:   "needs 12h rest after the 1700 NORMAL flight on Jan 05 26",
+		},
+		{
+			name:   "less than min_hours before the next day's flight",
+			policy: defaultRestPolicy(),
+			duties: []*Flight{testFlight("Jan 06 26", "0700", 2)},
+			flight: testFlight("Jan 05 26", "1700", 3),
+			want:   "needs 12h rest before the 0700 NORMAL flight on Jan 06 26",
+		},
+		{
+			name:   "same day left to the duty day policy",
+			policy: defaultRestPolicy(),
+			duties: []*Flight{testFlight("Jan 06 26", "0800", 2)},
+			flight: testFlight("Jan 06 26", "1200", 2),
+		},
+		{
+			name:   "min_hours turned off",
+			policy: &RestPolicy{},
+			duties: []*Flight{testFlight("Jan 05 26", "1700", 3)},
+			flight: testFlight("Jan 06 26", "0700", 2),
+		},
+		{
+			name:   "consecutive days at the limit",
+			policy: &RestPolicy{MaxConsecutiveDays: 3},
+			duties: []*Flight{testFlight("Jan 05 26", "0800", 2), testFlight("Jan 06 26", "0800", 2)},
+			flight: testFlight("Jan 07 26", "0800", 2),
+		},
+		{
+			name:   "consecutive days past the limit",
+			policy: &RestPolicy{MaxConsecutiveDays: 2},
+			duties: []*Flight{testFlight("Jan 05 26", "0800", 2), testFlight("Jan 06 26", "0800", 2)},
+			flight: testFlight("Jan 07 26", "0800", 2),
+			want:   "would fly 3 days in a row; at most 2",
+		},
+		{
+			name:   "day joining two runs",
+			policy: &RestPolicy{MaxConsecutiveDays: 2},
+			duties: []*Flight{testFlight("Jan 05 26", "0800", 2), testFlight("Jan 07 26", "0800", 2)},
+			flight: testFlight("Jan 06 26", "0800", 2),
+			want:   "would fly 3 days in a row; at most 2",
+		},
+		{
+			name:   "day off breaks the run",
+			policy: &RestPolicy{MaxConsecutiveDays: 2},
+			duties: []*Flight{testFlight("Jan 05 26", "0800", 2), testFlight("Jan 06 26", "0800", 2)},
+			flight: testFlight("Jan 08 26", "0800", 2),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.policy.violation(test.duties, test.flight); got != test.want {
+				t.Errorf("violation = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSolveFlightSchedulesRestsCrewForAnotherDay(t *testing.T) {
+	crew := []*CrewAvailability{}
+	for _, name := range []string{"Able", "Baker"} {
+		crew = append(crew, &CrewAvailability{
+			FirstName:      "Sam",
+			LastName:       name,
+			Rank:           "CW3",
+			Status:         "PI",
+			Availabilty:    map[string]bool{"Jan 05 26": true, "Jan 06 26": true},
+			Codes:          map[string]string{"Jan 05 26": "", "Jan 06 26": ""},
+			Qualifications: map[string]time.Time{},
+		})
+	}
+	crew[0].Qualifications["MTP"] = time.Time{}
+
+	// Able is cheaper for the evening flight, but is the only one who can fly the next morning's
+	rule := &CrewRule{Seats: map[string]SeatRange{"PI": {Min: 1, Max: 1}}}
+	flightSchedules := &FlightSchedules{Flights: []*Flight{
+		{Type: "NORMAL", Date: "Jan 05 26", Time: "1700", Duration: 3 * time.Hour, Rule: rule},
+		{Type: "MAINTENANCE", Date: "Jan 06 26", Time: "0700", Duration: 2 * time.Hour, Rule: rule, Requires: map[string][]string{"PI": {"MTP"}}},
+	}}
+
+	if err := NewSchedulePayload(crew).solveFlightSchedules(flightSchedules, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	got := []string{}
+	for _, flight := range flightSchedules.Flights {
+		for _, c := range flight.crew() {
+			got = append(got, c.LastName)
+		}
+	}
+	if want := []string{"Baker", "Able"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("crew = %q, want %q", got, want)
+	}
+	if len(flightSchedules.Unfilled) > 0 {
+		t.Errorf("Unfilled = %v, want none", flightSchedules.Unfilled)
+	}
+}
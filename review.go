@@ -0,0 +1,445 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/andlabs/ui"
+)
+
+const EMPTY_SEAT_CHOICE = "(empty)"
+
+// Lets the generated schedule be changed by hand before it's exported
+type reviewPage struct {
+	schedulePayload *SchedulePayload
+	flightSchedules *FlightSchedules
+	options         *GenerateOptions
+	days            []*reviewDay
+	summary         *ui.Label
+}
+
+type reviewDay struct {
+	page      *reviewPage
+	date      string
+	flights   []*reviewFlight
+	conflicts *ui.Label
+}
+
+type reviewFlight struct {
+	day        *reviewDay
+	flight     *Flight
+	candidates map[string][]*CrewAvailability // Key: crew status; Value: crew whose availability fits the flight
+	seats      map[string][]*ui.Combobox      // Key: crew status; Value: one dropdown per seat
+	locks      map[string][]*ui.Checkbox      // Parallel to seats; locked crew are kept when the schedule is re-solved
+}
+
+func showReviewPage(schedulePayload *SchedulePayload, flightSchedules *FlightSchedules, options *GenerateOptions) {
+	page := &reviewPage{
+		schedulePayload: schedulePayload,
+		flightSchedules: flightSchedules,
+		options:         options,
+	}
+
+	vbox := ui.NewVerticalBox()
+	vbox.SetPadded(true)
+
+	vbox.Append(ui.NewLabel("Check each day's crew and change any seat before the schedule is exported."), false)
+	vbox.Append(ui.NewLabel("Locked crew stay where they are when the rest of the schedule is re-solved."), false)
+
+	tab := ui.NewTab()
+	for i, flightIndexes := range flightIndexesByDate(flightSchedules) {
+		day := page.newReviewDay(flightIndexes)
+		page.days = append(page.days, day)
+
+		tab.Append(day.date, day.makeControl())
+		tab.SetMargined(i, true)
+	}
+	vbox.Append(tab, true)
+
+	page.summary = ui.NewLabel("")
+	vbox.Append(page.summary, false)
+
+	buttons := ui.NewHorizontalBox()
+	buttons.SetPadded(true)
+
+	startOver := ui.NewButton("Start Over")
+	startOver.OnClicked(func(*ui.Button) {
+		showDatePage()
+	})
+	buttons.Append(startOver, false)
+
+	resolve := ui.NewButton("Re-solve")
+	resolve.OnClicked(func(*ui.Button) {
+		page.resolve()
+	})
+	buttons.Append(resolve, false)
+
+	layout := ui.NewCombobox()
+	for i, l := range layouts {
+		layout.Append(strings.ToUpper(l[:1]) + l[1:] + " layout")
+		if l == page.options.Layout {
+			layout.SetSelected(i)
+		}
+	}
+	layout.OnSelected(func(c *ui.Combobox) {
+		page.options.Layout = layouts[c.Selected()]
+	})
+	buttons.Append(layout, false)
+
+	export := ui.NewButton("Export")
+	export.OnClicked(func(*ui.Button) {
+		page.export()
+	})
+	buttons.Append(export, false)
+
+	vbox.Append(buttons, false)
+
+	for _, day := range page.days {
+		day.check()
+	}
+	page.summarize()
+
+	mainwin.SetChild(vbox)
+}
+
+func (page *reviewPage) newReviewDay(flightIndexes []int) *reviewDay {
+	day := &reviewDay{
+		page: page,
+		date: page.flightSchedules.Flights[flightIndexes[0]].Date,
+	}
+
+	for _, flightIndex := range flightIndexes {
+		rf := &reviewFlight{
+			day:        day,
+			flight:     page.flightSchedules.Flights[flightIndex],
+			candidates: make(map[string][]*CrewAvailability),
+			seats:      make(map[string][]*ui.Combobox),
+			locks:      make(map[string][]*ui.Checkbox),
+		}
+
+		// Crew of each seat's own status come first, then anyone who can substitute for them
+		for _, status := range seatStatuses {
+			for _, substitutes := range []bool{false, true} {
+				for _, crew := range page.schedulePayload.CrewAvailability {
+					if (crew.Status != status) != substitutes {
+						continue
+					}
+					if _, ok := flightConfig.Substitutions.penalty(status, crew.Status); ok && crew.canFly(rf.flight) && crew.isQualifiedFor(rf.flight, status) {
+						rf.candidates[status] = append(rf.candidates[status], crew)
+					}
+				}
+			}
+		}
+
+		day.flights = append(day.flights, rf)
+	}
+
+	return day
+}
+
+func (day *reviewDay) makeControl() ui.Control {
+	vbox := ui.NewVerticalBox()
+	vbox.SetPadded(true)
+
+	grid := ui.NewGrid()
+	grid.SetPadded(true)
+
+	for col, heading := range append([]string{"Time", "Flight Type"}, seatStatuses...) {
+		grid.Append(ui.NewLabel(heading), col, 0, 1, 1, false, ui.AlignStart, false, ui.AlignCenter)
+	}
+
+	for i, rf := range day.flights {
+		row := i + 1
+
+		grid.Append(ui.NewLabel(rf.flight.Time), 0, row, 1, 1, false, ui.AlignStart, false, ui.AlignStart)
+		grid.Append(ui.NewLabel(rf.flight.Type), 1, row, 1, 1, false, ui.AlignStart, false, ui.AlignStart)
+
+		for col, status := range seatStatuses {
+			grid.Append(rf.makeSeatsControl(status), col+2, row, 1, 1, true, ui.AlignFill, false, ui.AlignStart)
+		}
+	}
+
+	vbox.Append(grid, false)
+
+	day.conflicts = ui.NewLabel("")
+	vbox.Append(day.conflicts, false)
+
+	return vbox
+}
+
+// A dropdown for every seat of the status, plus a button to add another seat while the flight can take more crew
+func (rf *reviewFlight) makeSeatsControl(status string) ui.Control {
+	vbox := ui.NewVerticalBox()
+	vbox.SetPadded(true)
+
+	if rf.flight.MaxSeats[status] == 0 {
+		vbox.Append(ui.NewLabel("-"), false)
+		return vbox
+	}
+
+	seated := []*CrewMember{}
+	for _, crew := range rf.flight.crew() {
+		if crew.seatStatus() == status {
+			seated = append(seated, crew)
+		}
+	}
+
+	seats := rf.flight.Seats[status]
+	if len(seated) > seats {
+		seats = len(seated)
+	}
+
+	for i := 0; i < seats; i++ {
+		var crew *CrewMember
+		if i < len(seated) {
+			crew = seated[i]
+		}
+		vbox.Append(rf.addSeat(status, crew), false)
+	}
+
+	if len(rf.seats[status]) < rf.flight.MaxSeats[status] {
+		button := ui.NewButton(fmt.Sprintf("Add %s", status))
+		button.OnClicked(func(*ui.Button) {
+			// Appending after the button would look odd, so the box is rebuilt with the new seat in place
+			vbox.Delete(len(rf.seats[status]))
+			vbox.Append(rf.addSeat(status, nil), false)
+
+			if len(rf.seats[status]) < rf.flight.MaxSeats[status] {
+				vbox.Append(button, false)
+			}
+		})
+		vbox.Append(button, false)
+	}
+
+	return vbox
+}
+
+// Dropdown of the crew who could take the seat, with crew selected, and a box to lock them in
+func (rf *reviewFlight) addSeat(status string, crew *CrewMember) ui.Control {
+	candidates := rf.candidates[status]
+
+	combobox := ui.NewCombobox()
+	combobox.Append(EMPTY_SEAT_CHOICE)
+	for _, candidate := range candidates {
+		label := fmt.Sprintf("%s %s %s", candidate.Rank, candidate.FirstName, candidate.LastName)
+		if candidate.Status != status {
+			label = fmt.Sprintf("%s (%s)", label, candidate.Status)
+		}
+		combobox.Append(label)
+	}
+
+	combobox.SetSelected(0)
+	if crew != nil {
+		for i, candidate := range candidates {
+			if candidate.FirstName == crew.FirstName && candidate.LastName == crew.LastName {
+				combobox.SetSelected(i + 1)
+				break
+			}
+		}
+	}
+
+	lock := ui.NewCheckbox("Lock")
+	lock.SetChecked(crew != nil && crew.Pinned)
+	lock.OnToggled(func(*ui.Checkbox) {
+		rf.update()
+	})
+
+	combobox.OnSelected(func(*ui.Combobox) {
+		// Crew picked by hand are locked in, since that's usually why they were picked
+		lock.SetChecked(combobox.Selected() > 0)
+
+		rf.update()
+		// Rest between days can change on the days either side
+		for _, day := range rf.day.page.days {
+			day.check()
+		}
+		rf.day.page.summarize()
+	})
+
+	rf.seats[status] = append(rf.seats[status], combobox)
+	rf.locks[status] = append(rf.locks[status], lock)
+
+	hbox := ui.NewHorizontalBox()
+	hbox.SetPadded(true)
+	hbox.Append(combobox, true)
+	hbox.Append(lock, false)
+
+	return hbox
+}
+
+// Seats the crew picked in the dropdowns
+func (rf *reviewFlight) update() {
+	rf.flight.PC = nil
+	rf.flight.PIs = nil
+	rf.flight.FEs = nil
+	rf.flight.CEs = nil
+
+	for _, status := range seatStatuses {
+		candidates := rf.candidates[status]
+
+		for i, combobox := range rf.seats[status] {
+			selected := combobox.Selected()
+			if selected <= 0 || selected > len(candidates) {
+				continue
+			}
+
+			crew := NewCrewMember(candidates[selected-1])
+			crew.Pinned = rf.locks[status][i].Checked()
+			if crew.Status != status {
+				crew.Seat = status
+			}
+			seatCrew(rf.flight, crew)
+		}
+	}
+
+	rf.day.page.flightSchedules.Reviewed = true
+}
+
+func (day *reviewDay) check() {
+	conflicts := day.conflictList()
+	if len(conflicts) == 0 {
+		day.conflicts.SetText("No conflicts.")
+		return
+	}
+	day.conflicts.SetText("Conflicts:\n" + strings.Join(conflicts, "\n"))
+}
+
+// Everything wrong with the day's crew: duty day conflicts and broken rest and pairing rules
+func (day *reviewDay) conflictList() []string {
+	conflicts := append(dayConflicts(day.flightList()), restViolations(day.page.flightSchedules, day.date)...)
+	return append(conflicts, pairingViolations(day.page.flightSchedules, day.date)...)
+}
+
+func (day *reviewDay) flightList() []*Flight {
+	flights := []*Flight{}
+	for _, rf := range day.flights {
+		flights = append(flights, rf.flight)
+	}
+	return flights
+}
+
+func (page *reviewPage) summarize() {
+	var conflicts, unfilled int
+
+	for _, day := range page.days {
+		conflicts += len(day.conflictList())
+	}
+
+	for _, flight := range page.flightSchedules.Flights {
+		for _, status := range seatStatuses {
+			unfilled += flight.openSeats(status)
+		}
+	}
+
+	page.summary.SetText(fmt.Sprintf("%d conflicts, %d unfilled seats", conflicts, unfilled))
+}
+
+// Solves the schedule again with every locked seat pinned
+func (page *reviewPage) resolve() {
+	var (
+		pins            = []*Pin{}
+		flightSchedules = &FlightSchedules{Flights: []*Flight{}}
+	)
+
+	for _, flight := range page.flightSchedules.Flights {
+		empty := flight.withoutCrew()
+		flightSchedules.Flights = append(flightSchedules.Flights, empty)
+
+		for _, crew := range flight.crew() {
+			if !crew.Pinned {
+				continue
+			}
+
+			pins = append(pins, &Pin{
+				Date:      flight.Date,
+				Time:      flight.Time,
+				Type:      flight.Type,
+				FirstName: crew.FirstName,
+				LastName:  crew.LastName,
+				Source:    "review",
+				Seat:      crew.Seat,
+				flight:    empty,
+			})
+		}
+	}
+
+	page.schedulePayload.Pins = pins
+
+	err := page.schedulePayload.solveFlightSchedules(flightSchedules, nil)
+	if err != nil {
+		ui.MsgBoxError(mainwin, "Unable to re-solve flight schedules", err.Error())
+		return
+	}
+
+	showReviewPage(page.schedulePayload, flightSchedules, page.options)
+}
+
+func (page *reviewPage) export() {
+	for _, day := range page.days {
+		if len(day.conflictList()) > 0 {
+			ui.MsgBoxError(mainwin, "Conflicts in the schedule", fmt.Sprintf("Resolve the conflicts on %s before exporting.", day.date))
+			return
+		}
+	}
+
+	if page.flightSchedules.Reviewed {
+		findUnfilledSeats(page.flightSchedules)
+		explainUnfilledSeats(page.schedulePayload, page.flightSchedules)
+	}
+
+	err := exportSchedule(page.schedulePayload, page.flightSchedules, page.options)
+	if err != nil {
+		ui.MsgBoxError(mainwin, "Unable to export flight schedules", err.Error())
+		return
+	}
+
+	if len(page.flightSchedules.Unfilled) > 0 {
+		seats := []string{}
+		for _, seat := range page.flightSchedules.Unfilled {
+			seats = append(seats, seat.String())
+		}
+		ui.MsgBox(mainwin, "Unfilled seats", fmt.Sprintf("Exported with %d unfilled seats:\n%s", len(seats), strings.Join(seats, "\n")))
+	}
+
+	ui.Quit()
+}
+
+// Problems with one day's crew that would stop the schedule being flown as is, e.g. someone on more flights
+// than their duty day policy allows
+func dayConflicts(flights []*Flight) []string {
+	var (
+		conflicts     = []string{}
+		names         = []string{}
+		crewByName    = make(map[string]*CrewMember)
+		flightsByCrew = make(map[string][]*Flight)
+	)
+
+	for _, flight := range flights {
+		for _, crew := range flight.crew() {
+			name := crewName(crew.FirstName, crew.LastName)
+			if _, ok := flightsByCrew[name]; !ok {
+				names = append(names, name)
+				crewByName[name] = crew
+			}
+			flightsByCrew[name] = append(flightsByCrew[name], flight)
+		}
+	}
+
+	sort.Strings(names)
+	for _, name := range names {
+		var (
+			crew    = crewByName[name]
+			policy  = flightConfig.dutyDayPolicy(crew.FirstName, crew.LastName, crew.Status)
+			flights = flightsByCrew[name]
+		)
+
+		for i, flight := range flights {
+			if reason := policy.violation(flights[:i], flight); reason != "" {
+				conflicts = append(conflicts, fmt.Sprintf("%s can't fly the %s %s flight: %s", name, flight.Time, flight.Type, reason))
+			}
+		}
+	}
+
+	return conflicts
+}
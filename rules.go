@@ -0,0 +1,211 @@
+package main
+
+import "fmt"
+
+var flightTypes = []string{"MAINTENANCE", "TRAINING", "NORMAL"}
+
+/*
+Crew requirements for a flight type, or a template that needs its own, e.g. a training sim with a PC and an FE plus either 3 CEs or 2 PIs:
+
+	{
+		"seats": {"PC": {"min": 1, "max": 1}, "FE": {"min": 1, "max": 1}},
+		"alternatives": [
+			{"PI": {"min": 1, "max": 1}, "CE": {"min": 3, "max": 3}},
+			{"PI": {"min": 2, "max": 2}, "CE": {"min": 1, "max": 1}}
+		]
+	}
+
+The solver fills the min seats of "seats" plus those of whichever alternative it can crew; max caps the crew that
+can be added to a seat by hand.
+*/
+type CrewRule struct {
+	Seats        map[string]SeatRange   `json:"seats"`
+	Alternatives []map[string]SeatRange `json:"alternatives"`
+}
+
+type SeatRange struct {
+	Min int `json:"min"`
+	Max int `json:"max"`
+}
+
+func defaultCrewRules() map[string]*CrewRule {
+	return map[string]*CrewRule{
+		"MAINTENANCE": {
+			Seats: map[string]SeatRange{
+				"PC": {Min: 1, Max: 1},
+				"PI": {Min: 1, Max: 2},
+				"FE": {Min: 1, Max: 2},
+			},
+		},
+		"TRAINING": {
+			Seats: map[string]SeatRange{
+				"PC": {Min: 1, Max: 1},
+				"FE": {Min: 1, Max: 2},
+			},
+			Alternatives: []map[string]SeatRange{
+				{"PI": {Min: 1, Max: 2}, "CE": {Min: 3, Max: 4}},
+				{"PI": {Min: 2, Max: 3}, "CE": {Min: 1, Max: 2}},
+			},
+		},
+		"NORMAL": {
+			Seats: map[string]SeatRange{
+				"PC": {Min: 1, Max: 1},
+				"PI": {Min: 1, Max: 2},
+				"FE": {Min: 1, Max: 2},
+				"CE": {Min: 1, Max: 3},
+			},
+		},
+	}
+}
+
+// Rule for the flight's crew: its template's if it has one, otherwise its type's
+func (f *Flight) crewRule() *CrewRule {
+	if f.Rule != nil {
+		return f.Rule
+	}
+	return flightConfig.Crew[f.Type]
+}
+
+// Every crew composition a flight of this type can fly with, one per alternative
+func (r *CrewRule) compositions() []map[string]SeatRange {
+	if len(r.Alternatives) == 0 {
+		return []map[string]SeatRange{r.Seats}
+	}
+
+	compositions := []map[string]SeatRange{}
+	for _, alternative := range r.Alternatives {
+		composition := make(map[string]SeatRange)
+		for status, seats := range r.Seats {
+			composition[status] = seats
+		}
+		for status, seats := range alternative {
+			composition[status] = seats
+		}
+		compositions = append(compositions, composition)
+	}
+
+	return compositions
+}
+
+// Most crew of the status any composition can take
+func (r *CrewRule) maxSeats(status string) int {
+	max := 0
+	for _, composition := range r.compositions() {
+		if composition[status].Max > max {
+			max = composition[status].Max
+		}
+	}
+	return max
+}
+
+// Indexes of the compositions with room for the given number of crew of each status
+func (r *CrewRule) compositionsFitting(seated map[string]int) []int {
+	fits := []int{}
+
+	for i, composition := range r.compositions() {
+		fit := true
+		for status, n := range seated {
+			if n > composition[status].Max {
+				fit = false
+			}
+		}
+
+		if fit {
+			fits = append(fits, i)
+		}
+	}
+
+	return fits
+}
+
+func validateCrewRules(rules map[string]*CrewRule) error {
+	for _, flightType := range flightTypes {
+		if rules[flightType] == nil {
+			return fmt.Errorf("crew has no rules for %s flights", flightType)
+		}
+	}
+
+	for flightType := range rules {
+		if !isFlightType(flightType) {
+			return fmt.Errorf("crew has rules for unknown flight type %q", flightType)
+		}
+	}
+
+	for _, flightType := range flightTypes {
+		if err := rules[flightType].validate("crew." + flightType); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Checks a rule found at the given path in the flight config, e.g. crew.TRAINING
+func (r *CrewRule) validate(path string) error {
+	if err := validateSeatRanges(r.Seats); err != nil {
+		return fmt.Errorf("%s.seats: %s", path, err)
+	}
+
+	for i, alternative := range r.Alternatives {
+		if err := validateSeatRanges(alternative); err != nil {
+			return fmt.Errorf("%s.alternatives[%d]: %s", path, i, err)
+		}
+
+		for status := range alternative {
+			if _, ok := r.Seats[status]; ok {
+				return fmt.Errorf("%s.alternatives[%d]: %s is already set in seats", path, i, status)
+			}
+		}
+	}
+
+	for i, composition := range r.compositions() {
+		required := 0
+		for _, seats := range composition {
+			required += seats.Min
+		}
+
+		if required == 0 {
+			if len(r.Alternatives) > 0 {
+				return fmt.Errorf("%s.alternatives[%d] leaves the flight with no required crew", path, i)
+			}
+			return fmt.Errorf("%s has no required crew", path)
+		}
+	}
+
+	return nil
+}
+
+func validateSeatRanges(seatRanges map[string]SeatRange) error {
+	for status := range seatRanges {
+		if !isCrewStatus(status) {
+			return fmt.Errorf("unknown crew status %q", status)
+		}
+	}
+
+	for _, status := range seatStatuses {
+		seats, ok := seatRanges[status]
+		if !ok {
+			continue
+		}
+		if seats.Min < 0 {
+			return fmt.Errorf("%s min is negative", status)
+		}
+		if seats.Max < seats.Min {
+			return fmt.Errorf("%s max %d is below min %d", status, seats.Max, seats.Min)
+		}
+		if status == "PC" && seats.Max > 1 {
+			return fmt.Errorf("PC max can't be more than 1; a flight has a single PC")
+		}
+	}
+
+	return nil
+}
+
+func isFlightType(flightType string) bool {
+	for _, t := range flightTypes {
+		if flightType == t {
+			return true
+		}
+	}
+	return false
+}
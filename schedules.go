@@ -0,0 +1,304 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/tealeg/xlsx"
+)
+
+const (
+	FLIGHTS_SHEET = "Flights"
+	GAPS_SHEET    = "Gaps"
+
+	FLIGHT_DATE_COL       = 0
+	FLIGHT_TYPE_COL       = 1
+	FLIGHT_TIME_COL       = 2
+	FLIGHT_STATUS_COL     = 3
+	FLIGHT_RANK_COL       = 4
+	FLIGHT_FIRST_NAME_COL = 5
+	FLIGHT_LAST_NAME_COL  = 6
+	FLIGHT_SEAT_COL       = 7 // Status of the seat when the crew member is substituting; blank otherwise
+)
+
+// Reads flight schedules written by either exportXLSXResult or exportJSONResult
+func readFlightSchedules(fileName string) (*FlightSchedules, error) {
+	if isJSONFile(fileName) {
+		log.Println("Reading", fileName)
+		return flightSchedulesFromJSON(fileName)
+	}
+	return flightSchedulesFromXLSX(fileName)
+}
+
+// Reads flight schedules back from a workbook written by exportXLSXResult, gaps included
+func flightSchedulesFromXLSX(fileName string) (*FlightSchedules, error) {
+	log.Println("Reading", fileName)
+	file, err := openWorkbook(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	sheet, ok := file.Sheet[FLIGHTS_SHEET]
+	if !ok {
+		return nil, &SheetMissingError{File: fileName, Sheet: FLIGHTS_SHEET}
+	}
+
+	flightSchedules := &FlightSchedules{Flights: []*Flight{}, Unfilled: []*UnfilledSeat{}}
+
+	problems := flightsFromSheet(sheet, fileName, flightSchedules)
+	if gapsSheet, ok := file.Sheet[GAPS_SHEET]; ok {
+		problems = append(problems, unfilledSeatsFromSheet(gapsSheet, fileName, flightSchedules)...)
+	}
+
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+
+	gapsByFlight := make(map[int]map[string]int)
+	for _, seat := range flightSchedules.Unfilled {
+		if gapsByFlight[seat.FlightIndex] == nil {
+			gapsByFlight[seat.FlightIndex] = make(map[string]int)
+		}
+		gapsByFlight[seat.FlightIndex][seat.Status]++
+	}
+
+	for i, flight := range flightSchedules.Flights {
+		flight.fitSeats(gapsByFlight[i])
+	}
+
+	return flightSchedules, nil
+}
+
+func flightsFromSheet(sheet *xlsx.Sheet, fileName string, flightSchedules *FlightSchedules) []error {
+	var (
+		problems = []error{}
+		flight   *Flight
+	)
+
+	for r, row := range sheet.Rows {
+		if r == 0 || row == nil {
+			continue
+		}
+
+		values := make([]string, FLIGHT_SEAT_COL+1)
+		for col := range values {
+			val, err := cellValue(row, col)
+			if err != nil {
+				problems = append(problems, &CellError{File: fileName, Sheet: sheet.Name, Row: r, Col: col, Problem: err.Error()})
+			}
+			values[col] = val
+		}
+
+		switch values[FLIGHT_DATE_COL] {
+		case "":
+			continue
+		case "-":
+			if flight == nil {
+				problems = append(problems, &CellError{File: fileName, Sheet: sheet.Name, Row: r, Col: FLIGHT_DATE_COL, Problem: "crew listed before any flight"})
+				continue
+			}
+
+			status := values[FLIGHT_STATUS_COL]
+			if !isCrewStatus(status) {
+				problems = append(problems, &CellError{File: fileName, Sheet: sheet.Name, Row: r, Col: FLIGHT_STATUS_COL, Problem: fmt.Sprintf("unknown crew status %q", status)})
+				continue
+			}
+
+			seat := values[FLIGHT_SEAT_COL]
+			if seat != "" && !isCrewStatus(seat) {
+				problems = append(problems, &CellError{File: fileName, Sheet: sheet.Name, Row: r, Col: FLIGHT_SEAT_COL, Problem: fmt.Sprintf("unknown crew status %q", seat)})
+				continue
+			}
+
+			seatCrew(flight, &CrewMember{
+				FirstName: values[FLIGHT_FIRST_NAME_COL],
+				LastName:  values[FLIGHT_LAST_NAME_COL],
+				Rank:      values[FLIGHT_RANK_COL],
+				Status:    status,
+				Seat:      seat,
+			})
+		default:
+			if _, err := time.Parse(FULL_DATE_FORMAT, values[FLIGHT_DATE_COL]); err != nil {
+				problems = append(problems, &CellError{File: fileName, Sheet: sheet.Name, Row: r, Col: FLIGHT_DATE_COL, Problem: fmt.Sprintf("%q is not a date like %s", values[FLIGHT_DATE_COL], FULL_DATE_FORMAT)})
+				flight = nil
+				continue
+			}
+			if !isFlightType(values[FLIGHT_TYPE_COL]) {
+				problems = append(problems, &CellError{File: fileName, Sheet: sheet.Name, Row: r, Col: FLIGHT_TYPE_COL, Problem: fmt.Sprintf("unknown flight type %q", values[FLIGHT_TYPE_COL])})
+				flight = nil
+				continue
+			}
+
+			flight = &Flight{
+				Type:     values[FLIGHT_TYPE_COL],
+				Date:     values[FLIGHT_DATE_COL],
+				Time:     values[FLIGHT_TIME_COL],
+				Duration: flightConfig.flightDuration(values[FLIGHT_TYPE_COL], values[FLIGHT_TIME_COL]),
+				Requires: flightConfig.flightRequirements(values[FLIGHT_TYPE_COL], values[FLIGHT_TIME_COL]),
+				Rule:     flightConfig.flightCrewRule(values[FLIGHT_TYPE_COL], values[FLIGHT_TIME_COL]),
+			}
+			flightSchedules.Flights = append(flightSchedules.Flights, flight)
+		}
+	}
+
+	return problems
+}
+
+// Reads the unfilled seats back from the gaps sheet, where each seat has a row for every crew member of its
+// status. A seat's rows end where the flight or status changes, or where a crew member comes up again
+func unfilledSeatsFromSheet(sheet *xlsx.Sheet, fileName string, flightSchedules *FlightSchedules) []error {
+	var (
+		problems = []error{}
+		seat     *UnfilledSeat
+		listed   map[string]bool                // Crew already in the current seat's rows
+		assigned = make(map[int]map[string]int) // Key: flight index; Value: unfilled seats by status
+	)
+
+	for r, row := range sheet.Rows {
+		if r == 0 || row == nil {
+			continue
+		}
+
+		values := make([]string, len(gapsSheetHeading))
+		for col := range values {
+			val, err := cellValue(row, col)
+			if err != nil {
+				problems = append(problems, &CellError{File: fileName, Sheet: sheet.Name, Row: r, Col: col, Problem: err.Error()})
+			}
+			values[col] = val
+		}
+
+		if values[FLIGHT_DATE_COL] == "" {
+			continue
+		}
+
+		var crew *CrewMember
+		name := "-"
+		if values[FLIGHT_FIRST_NAME_COL] != "-" || values[FLIGHT_LAST_NAME_COL] != "-" {
+			crew = &CrewMember{
+				FirstName: values[FLIGHT_FIRST_NAME_COL],
+				LastName:  values[FLIGHT_LAST_NAME_COL],
+				Rank:      values[FLIGHT_RANK_COL],
+				Status:    values[FLIGHT_STATUS_COL],
+			}
+			name = hoursKey(crew.FirstName, crew.LastName)
+		}
+
+		sameSeat := seat != nil && seat.Date == values[FLIGHT_DATE_COL] && seat.Type == values[FLIGHT_TYPE_COL] &&
+			seat.Time == values[FLIGHT_TIME_COL] && seat.Status == values[FLIGHT_STATUS_COL] && !listed[name]
+
+		if !sameSeat {
+			flightIndex := gapFlight(flightSchedules, values[FLIGHT_DATE_COL], values[FLIGHT_TIME_COL], values[FLIGHT_TYPE_COL], values[FLIGHT_STATUS_COL], assigned)
+			if flightIndex < 0 {
+				problems = append(problems, &CellError{File: fileName, Sheet: sheet.Name, Row: r, Col: FLIGHT_DATE_COL, Problem: fmt.Sprintf("no %s %s flight on %s in the %s sheet", values[FLIGHT_TIME_COL], values[FLIGHT_TYPE_COL], values[FLIGHT_DATE_COL], FLIGHTS_SHEET)})
+				seat = nil
+				continue
+			}
+
+			seat = &UnfilledSeat{
+				FlightIndex: flightIndex,
+				Date:        values[FLIGHT_DATE_COL],
+				Time:        values[FLIGHT_TIME_COL],
+				Type:        values[FLIGHT_TYPE_COL],
+				Status:      values[FLIGHT_STATUS_COL],
+				Exclusions:  []*Exclusion{},
+			}
+			listed = make(map[string]bool)
+			flightSchedules.Unfilled = append(flightSchedules.Unfilled, seat)
+
+			if assigned[flightIndex] == nil {
+				assigned[flightIndex] = make(map[string]int)
+			}
+			assigned[flightIndex][seat.Status]++
+		}
+
+		listed[name] = true
+		seat.Exclusions = append(seat.Exclusions, &Exclusion{Crew: crew, Reason: values[len(gapsSheetHeading)-1]})
+	}
+
+	return problems
+}
+
+// Flight an unfilled seat belongs to: of the flights at that date and time, the one with the fewest crew of the
+// status, counting the gaps already put on it. -1 if there's no such flight
+func gapFlight(flightSchedules *FlightSchedules, date string, flightTime string, flightType string, status string, assigned map[int]map[string]int) int {
+	var (
+		best      = -1
+		bestCount int
+	)
+
+	for i, flight := range flightSchedules.Flights {
+		if flight.Date != date || flight.Time != flightTime || flight.Type != flightType {
+			continue
+		}
+
+		count := assigned[i][status]
+		for _, crew := range flight.crew() {
+			if crew.seatStatus() == status {
+				count++
+			}
+		}
+
+		if best < 0 || count < bestCount {
+			best, bestCount = i, count
+		}
+	}
+
+	return best
+}
+
+// Same flight with nobody seated
+func (f *Flight) withoutCrew() *Flight {
+	return &Flight{
+		Type:     f.Type,
+		Date:     f.Date,
+		Time:     f.Time,
+		Duration: f.Duration,
+		Requires: f.Requires,
+		Rule:     f.Rule,
+	}
+}
+
+// Sets Seats and MaxSeats, since the crew composition isn't written out with the flight. Picks the first
+// composition that leaves exactly the given gaps open, or failing that the first with room for everyone seated
+func (f *Flight) fitSeats(gaps map[string]int) {
+	seated := make(map[string]int)
+	for _, crew := range f.crew() {
+		seated[crew.seatStatus()]++
+	}
+
+	rule := f.crewRule()
+	compositions := rule.compositions()
+	fits := rule.compositionsFitting(seated)
+
+	composition := compositions[0]
+	if len(fits) > 0 {
+		composition = compositions[fits[0]]
+	}
+
+	for _, i := range fits {
+		exact := true
+		for _, status := range seatStatuses {
+			open := compositions[i][status].Min - seated[status]
+			if open < 0 {
+				open = 0
+			}
+			if open != gaps[status] {
+				exact = false
+			}
+		}
+
+		if exact {
+			composition = compositions[i]
+			break
+		}
+	}
+
+	f.Seats = make(map[string]int)
+	f.MaxSeats = make(map[string]int)
+	for status, seats := range composition {
+		f.Seats[status] = seats.Min
+		f.MaxSeats[status] = seats.Max
+	}
+}
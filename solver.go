@@ -0,0 +1,943 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	PRIORITY_WEIGHT       = 1      // Cost per position a crew member sits below the top of their section in Troop to Task
+	REPEAT_FLIGHT_PENALTY = 5      // Cost per flight a crew member has already been given this week
+	MAX_SEARCH_NODES      = 50000  // Per combination of crew alternatives; the best roster found so far is kept if the search is cut off
+	MAX_DAY_SEARCH_NODES  = 400000 // Per day, shared out between the combinations still to be searched
+	MAX_COMBINATIONS      = 4096   // Per day; combinations of crew alternatives past this aren't tried
+	MAX_REST_RETRIES      = 8      // Times a schedule is solved again to free crew another day's flight left short of rest
+
+	NO_CREW    = -1 // Seat hasn't been looked at yet
+	EMPTY_SEAT = -2 // Seat was deliberately left unfilled
+
+	COMMON_SEAT = -1 // Seat every composition of the flight has, rather than one belonging to an alternative
+)
+
+var seatStatuses = []string{"PC", "PI", "FE", "CE"}
+
+type UnfilledSeat struct {
+	FlightIndex int
+	Date        string
+	Time        string
+	Type        string
+	Status      string
+	Exclusions  []*Exclusion // Why each crew member of the seat's status wasn't put in it
+}
+
+func (u *UnfilledSeat) String() string {
+	return fmt.Sprintf("%s seat on the %s %s flight on %s", u.Status, u.Time, u.Type, u.Date)
+}
+
+// A day whose search ran out of nodes or combinations before its roster was proven the best possible
+type CutOffDay struct {
+	Date        string
+	Unfilled    int
+	MinUnfilled int // Fewest unfilled seats any roster could have, as far as the search can tell
+}
+
+func (c *CutOffDay) String() string {
+	if c.Unfilled > c.MinUnfilled {
+		return fmt.Sprintf("The search for %s was cut off with %d seat(s) unfilled; as few as %d may be possible", c.Date, c.Unfilled, c.MinUnfilled)
+	}
+	return fmt.Sprintf("The search for %s was cut off; no more seats can be filled, but a fairer roster may exist", c.Date)
+}
+
+// A warning for each day that may have more seats unfilled than it needs to, and one for all the days that
+// only may have a fairer roster, since the search is often cut off before it can prove that
+func cutOffWarnings(days []*CutOffDay) []string {
+	var (
+		warnings = []string{}
+		fairness = []*CutOffDay{}
+	)
+
+	for _, day := range days {
+		if day.Unfilled > day.MinUnfilled {
+			warnings = append(warnings, day.String())
+		} else {
+			fairness = append(fairness, day)
+		}
+	}
+
+	if len(fairness) == 1 {
+		warnings = append(warnings, fairness[0].String())
+	} else if len(fairness) > 1 {
+		dates := []string{}
+		for _, day := range fairness {
+			dates = append(dates, day.Date)
+		}
+		warnings = append(warnings, fmt.Sprintf("The search was cut off on %s; no more seats can be filled, but fairer rosters may exist", strings.Join(dates, ", ")))
+	}
+
+	return warnings
+}
+
+// A single crew position on a single flight
+type seat struct {
+	flight      int // Index into FlightSchedules.Flights
+	status      string
+	alternative int // Index into the flight type's CrewRule.Alternatives, or COMMON_SEAT
+}
+
+type scheduleSolver struct {
+	crew            []*CrewAvailability
+	flightSchedules *FlightSchedules
+	priority        []int // Position of each crew member within their status section
+	flightsThisWeek []int
+	recentFlights   []int     // Flights from the history window before this schedule
+	hours           []float64 // Hours from info.xlsx plus the flights scheduled so far
+	hoursRank       []int
+	pinned          map[int][]int          // Key: flight index; Value: crew pinned to it
+	pinnedSeats     map[int]map[int]string // Key: flight index; Value: seat status of each crew index pinned as a substitute
+	duties          [][]*Flight            // Flights each crew member is seated on so far, plus those they're pinned to
+	dutyDay         []*DutyDayPolicy
+	pairing         *PairingRules        // nil without a pairings file
+	crewKeys        []string             // Hours key of each crew member, for the pairing rules
+	crewByKey       map[string]int       // Key: hours key; Value: crew index
+	previous        map[int]map[int]bool // Key: flight index; Value: crew indexes on it in the previous schedule; nil unless re-planning
+	reserved        map[int]map[int]bool // Key: flight index; Value: crew indexes kept off it so they can rest for another day's
+}
+
+// State for the search over a single day's seats
+type daySearch struct {
+	solver      *scheduleSolver
+	seats       []seat
+	candidates  [][]int // Crew indexes qualified and available for each seat, cheapest first
+	costs       [][]int // Cost of each crew index for each seat
+	twins       [][]int // Other seats of the same status on the same flight
+	assigned    []int
+	today       [][]*Flight          // Flights each crew member is seated on today, starting with those they're pinned to
+	disallowed  map[int]map[int]bool // Key: flight index; Value: alternatives without room for the flight's pinned crew
+	flightSeats map[int][]int        // Key: flight index; Value: its seats in the combination being searched
+	remaining   map[int]int          // Key: flight index; Value: its seats not yet decided
+
+	choiceFlights []int       // Flights with crew alternatives
+	alternatives  map[int]int // Key: flight index; Value: number of alternatives
+	choices       map[int]int // Key: flight index; Value: alternative being searched
+
+	best            []int
+	bestChoices     map[int]int
+	bestUnfilled    int
+	bestChanged     int // Seats a re-plan gives to crew who weren't on the flight before
+	bestSubstituted int // Seats filled by crew of another status
+	bestCost        int
+	minUnfilled     int  // Lower bound from the cheapest assignment of crew to seats; a roster reaching it can't be beaten on coverage
+	minChanged      int  // Lower bound on changed seats among rosters reaching minUnfilled
+	minSubstituted  int  // Lower bound on substitutes among rosters reaching both
+	minCost         int  // Lower bound on cost among rosters reaching all three
+	nodes           int  // Searched for the current combination
+	budget          int  // Nodes the current combination may search
+	cutOff          bool // A combination ran out of nodes
+	truncated       bool // Combinations past MAX_COMBINATIONS weren't tried
+}
+
+func newScheduleSolver(s *SchedulePayload, flightSchedules *FlightSchedules, pinned map[int][]int, pinnedSeats map[int]map[int]string) *scheduleSolver {
+	var (
+		priority         = make([]int, len(s.CrewAvailability))
+		recentFlights    = make([]int, len(s.CrewAvailability))
+		duties           = make([][]*Flight, len(s.CrewAvailability))
+		dutyDay          = make([]*DutyDayPolicy, len(s.CrewAvailability))
+		crewKeys         = make([]string, len(s.CrewAvailability))
+		crewByKey        = make(map[string]int)
+		positionByStatus = make(map[string]int)
+	)
+
+	for i, crew := range s.CrewAvailability {
+		priority[i] = positionByStatus[crew.Status]
+		positionByStatus[crew.Status]++
+		crewKeys[i] = hoursKey(crew.FirstName, crew.LastName)
+		recentFlights[i] = s.RecentFlights[crewKeys[i]]
+		dutyDay[i] = flightConfig.dutyDayPolicy(crew.FirstName, crew.LastName, crew.Status)
+		crewByKey[crewKeys[i]] = i
+	}
+
+	// Later days' pins count towards rest before they're seated
+	for flightIndex, crewIndexes := range pinned {
+		for _, crewIndex := range crewIndexes {
+			duties[crewIndex] = append(duties[crewIndex], flightSchedules.Flights[flightIndex])
+		}
+	}
+
+	var previous map[int]map[int]bool
+	if s.Previous != nil {
+		previous = make(map[int]map[int]bool)
+		for flightIndex, flight := range flightSchedules.Flights {
+			keys, ok := s.Previous[flight]
+			if !ok {
+				continue
+			}
+			previous[flightIndex] = make(map[int]bool)
+			for _, key := range keys {
+				if crewIndex, ok := crewByKey[key]; ok {
+					previous[flightIndex][crewIndex] = true
+				}
+			}
+		}
+	}
+
+	return &scheduleSolver{
+		crew:            s.CrewAvailability,
+		flightSchedules: flightSchedules,
+		priority:        priority,
+		flightsThisWeek: make([]int, len(s.CrewAvailability)),
+		recentFlights:   recentFlights,
+		hours:           startingHours(s.CrewAvailability),
+		pinned:          pinned,
+		pinnedSeats:     pinnedSeats,
+		duties:          duties,
+		dutyDay:         dutyDay,
+		pairing:         s.Pairing,
+		crewKeys:        crewKeys,
+		crewByKey:       crewByKey,
+		previous:        previous,
+	}
+}
+
+// Crews every day that isn't frozen. Days are solved one at a time, so a seat can be left empty because the only
+// crew who could fill it were given a flight on another day that leaves them too little rest. The schedule is then
+// solved again with each of them kept off that flight in turn, up to MAX_REST_RETRIES times, keeping whichever
+// solve leaves the fewest seats unfilled
+func solveSchedule(s *SchedulePayload, flightSchedules *FlightSchedules, frozen map[string]bool, pinned map[int][]int, pinnedSeats map[int]map[int]string) {
+	var (
+		reserved = make(map[int]map[int]bool)
+		solver   = newScheduleSolver(s, flightSchedules, pinned, pinnedSeats)
+	)
+	solver.solveDays(frozen)
+
+	var (
+		blocks   = solver.restBlocks(frozen)
+		unfilled = len(flightSchedules.Unfilled)
+		kept     = true // Whether the schedule holds the roster for reserved
+	)
+	for i, block := range blocks {
+		if i == MAX_REST_RETRIES || unfilled == 0 {
+			break
+		}
+
+		retry := make(map[int]map[int]bool)
+		for flightIndex, crewIndexes := range reserved {
+			retry[flightIndex] = crewIndexes
+		}
+		retry[block.flight] = make(map[int]bool)
+		for crewIndex := range reserved[block.flight] {
+			retry[block.flight][crewIndex] = true
+		}
+		retry[block.flight][block.crew] = true
+
+		flightSchedules.clearSolved(frozen)
+		solver = newScheduleSolver(s, flightSchedules, pinned, pinnedSeats)
+		solver.reserved = retry
+		solver.solveDays(frozen)
+
+		kept = len(flightSchedules.Unfilled) < unfilled
+		if kept {
+			reserved, unfilled = retry, len(flightSchedules.Unfilled)
+		}
+	}
+
+	if !kept {
+		flightSchedules.clearSolved(frozen)
+		solver = newScheduleSolver(s, flightSchedules, pinned, pinnedSeats)
+		solver.reserved = reserved
+		solver.solveDays(frozen)
+	}
+}
+
+func (solver *scheduleSolver) solveDays(frozen map[string]bool) {
+	for _, day := range flightIndexesByDate(solver.flightSchedules) {
+		if frozen[solver.flightSchedules.Flights[day[0]].Date] {
+			solver.countSeated(day)
+			continue
+		}
+		solver.solveDay(day)
+	}
+}
+
+// Takes the crew off every flight that isn't on a frozen day, along with the unfilled seats and cut off days
+func (f *FlightSchedules) clearSolved(frozen map[string]bool) {
+	for _, flight := range f.Flights {
+		if !frozen[flight.Date] {
+			flight.PC, flight.PIs, flight.FEs, flight.CEs = nil, nil, nil, nil
+		}
+	}
+	f.Unfilled = nil
+	f.CutOff = nil
+}
+
+// A crew member who could fill an unfilled seat but for the rest they need around a flight the solver gave them
+type restBlock struct {
+	flight int // Index into FlightSchedules.Flights of the flight in the way
+	crew   int
+}
+
+// Every crew member kept out of an unfilled seat only by the rest policy and a single flight the solver seated
+// them on, rather than one they're pinned to or that's on a frozen day, along with that flight
+func (solver *scheduleSolver) restBlocks(frozen map[string]bool) []restBlock {
+	var (
+		blocks        = []restBlock{}
+		found         = make(map[restBlock]bool)
+		indexByFlight = make(map[*Flight]int)
+		flights       = solver.flightSchedules.Flights
+		pinnedOn      = make(map[restBlock]bool)
+	)
+
+	for i, flight := range flights {
+		indexByFlight[flight] = i
+	}
+	for flightIndex, crewIndexes := range solver.pinned {
+		for _, crewIndex := range crewIndexes {
+			pinnedOn[restBlock{flight: flightIndex, crew: crewIndex}] = true
+		}
+	}
+
+	for _, seat := range solver.flightSchedules.Unfilled {
+		flight := flights[seat.FlightIndex]
+
+		for crewIndex, crew := range solver.crew {
+			if _, ok := flightConfig.Substitutions.penalty(seat.Status, crew.Status); !ok {
+				continue
+			}
+			if !crew.canFly(flight) || !crew.isQualifiedFor(flight, seat.Status) || solver.reserved[seat.FlightIndex][crewIndex] {
+				continue
+			}
+
+			duties, today := solver.duties[crewIndex], []*Flight{}
+			for _, duty := range duties {
+				if duty.Date == flight.Date {
+					today = append(today, duty)
+				}
+			}
+			policy := solver.dutyDay[crewIndex]
+			if len(today) >= policy.MaxFlights || policy.violation(today, flight) != "" {
+				continue
+			}
+			if flightConfig.Rest.violation(duties, flight) == "" {
+				continue
+			}
+
+			for i, duty := range duties {
+				block := restBlock{flight: indexByFlight[duty], crew: crewIndex}
+				if frozen[duty.Date] || pinnedOn[block] || found[block] {
+					continue
+				}
+
+				others := append(append([]*Flight{}, duties[:i]...), duties[i+1:]...)
+				if flightConfig.Rest.violation(others, flight) == "" {
+					found[block] = true
+					blocks = append(blocks, block)
+				}
+			}
+		}
+	}
+
+	return blocks
+}
+
+// Groups flight indexes by date, keeping the order the dates first appear in
+func flightIndexesByDate(flightSchedules *FlightSchedules) [][]int {
+	var (
+		days      = [][]int{}
+		dayByDate = make(map[string]int)
+	)
+
+	for i, flight := range flightSchedules.Flights {
+		day, ok := dayByDate[flight.Date]
+		if !ok {
+			day = len(days)
+			dayByDate[flight.Date] = day
+			days = append(days, []int{})
+		}
+		days[day] = append(days[day], i)
+	}
+
+	return days
+}
+
+func (solver *scheduleSolver) solveDay(flightIndexes []int) {
+	solver.hoursRank = solver.hoursRanks()
+
+	var (
+		d            = solver.newDaySearch(flightIndexes)
+		combinations = d.combinations()
+		left         = MAX_DAY_SEARCH_NODES
+	)
+	for i, combination := range combinations {
+		// Combinations are sorted by their bounds, so once one can't beat the best roster none after it can
+		if !d.beatsBest(seatBound{combination.minUnfilled, combination.minChanged, combination.minSubstituted, combination.minCost}) || d.isOptimal() {
+			break
+		}
+
+		// Every combination that could still win gets a share of the day's nodes, and whatever an easy one
+		// leaves unused goes to the ones after it
+		d.budget = left / (len(combinations) - i)
+		if d.budget > MAX_SEARCH_NODES {
+			d.budget = MAX_SEARCH_NODES
+		}
+		d.nodes = 0
+
+		d.choose(combination.choices)
+		d.search(seatBound{})
+		left -= d.nodes
+	}
+
+	if d.truncated && d.bestUnfilled > 0 {
+		// Untried combinations might fill any of the seats
+		d.minUnfilled = 0
+		d.cutOff = true
+	}
+	if d.cutOff && !d.isOptimal() {
+		solver.flightSchedules.CutOff = append(solver.flightSchedules.CutOff, &CutOffDay{
+			Date:        solver.flightSchedules.Flights[flightIndexes[0]].Date,
+			Unfilled:    d.bestUnfilled,
+			MinUnfilled: d.minUnfilled,
+		})
+	}
+
+	for _, flightIndex := range flightIndexes {
+		flight := solver.flightSchedules.Flights[flightIndex]
+		flight.Seats = make(map[string]int)
+		flight.MaxSeats = make(map[string]int)
+
+		for status, seats := range flight.crewRule().compositions()[d.bestChoices[flightIndex]] {
+			flight.Seats[status] = seats.Min
+			flight.MaxSeats[status] = seats.Max
+		}
+
+		for _, crewIndex := range solver.pinned[flightIndex] {
+			solver.seatCrewIndex(flight, crewIndex, pinnedSeat(solver.crew, crewIndex, solver.pinnedSeats[flightIndex]), true)
+		}
+	}
+
+	for i, crewIndex := range d.best {
+		st := d.seats[i]
+		flight := solver.flightSchedules.Flights[st.flight]
+
+		if st.alternative != COMMON_SEAT && st.alternative != d.bestChoices[st.flight] {
+			continue
+		}
+
+		if crewIndex < 0 {
+			solver.flightSchedules.Unfilled = append(solver.flightSchedules.Unfilled, &UnfilledSeat{
+				FlightIndex: st.flight,
+				Date:        flight.Date,
+				Time:        flight.Time,
+				Type:        flight.Type,
+				Status:      st.status,
+			})
+			continue
+		}
+
+		solver.seatCrewIndex(flight, crewIndex, st.status, false)
+	}
+}
+
+// Counts crew already on the flights towards fairness, as if the solver had seated them
+func (solver *scheduleSolver) countSeated(flightIndexes []int) {
+	crewByName := make(map[string]int)
+	for i, crew := range solver.crew {
+		crewByName[hoursKey(crew.FirstName, crew.LastName)] = i
+	}
+
+	for _, flightIndex := range flightIndexes {
+		flight := solver.flightSchedules.Flights[flightIndex]
+		for _, crew := range flight.crew() {
+			if crewIndex, ok := crewByName[hoursKey(crew.FirstName, crew.LastName)]; ok {
+				solver.flightsThisWeek[crewIndex]++
+				solver.hours[crewIndex] += flight.Duration.Hours()
+				solver.duties[crewIndex] = append(solver.duties[crewIndex], flight)
+			}
+		}
+	}
+}
+
+// Seats the crew member in a seat of the status, which is only ever another status's when they're substituting
+func (solver *scheduleSolver) seatCrewIndex(flight *Flight, crewIndex int, status string, pinned bool) {
+	crew := NewCrewMember(solver.crew[crewIndex])
+	crew.Pinned = pinned
+	if status != crew.Status {
+		crew.Seat = status
+	}
+
+	seatCrew(flight, crew)
+	solver.flightsThisWeek[crewIndex]++
+	solver.hours[crewIndex] += flight.Duration.Hours()
+	if !pinned {
+		solver.duties[crewIndex] = append(solver.duties[crewIndex], flight)
+	}
+}
+
+func (solver *scheduleSolver) newDaySearch(flightIndexes []int) *daySearch {
+	d := &daySearch{
+		solver:       solver,
+		today:        make([][]*Flight, len(solver.crew)),
+		disallowed:   make(map[int]map[int]bool),
+		alternatives: make(map[int]int),
+		bestChoices:  make(map[int]int),
+	}
+
+	for _, flightIndex := range flightIndexes {
+		flight := solver.flightSchedules.Flights[flightIndex]
+		rule := flight.crewRule()
+
+		// Pinned crew take seats before the search starts
+		pinned := make(map[string]int)
+		for _, crewIndex := range solver.pinned[flightIndex] {
+			pinned[pinnedSeat(solver.crew, crewIndex, solver.pinnedSeats[flightIndex])]++
+			d.today[crewIndex] = append(d.today[crewIndex], flight)
+		}
+
+		d.addSeats(flightIndex, rule.Seats, COMMON_SEAT, pinned)
+		for alternative, seatRanges := range rule.Alternatives {
+			d.addSeats(flightIndex, seatRanges, alternative, pinned)
+		}
+
+		if len(rule.Alternatives) > 0 {
+			d.choiceFlights = append(d.choiceFlights, flightIndex)
+			d.alternatives[flightIndex] = len(rule.Alternatives)
+
+			d.disallowed[flightIndex] = make(map[int]bool)
+			for alternative := range rule.Alternatives {
+				d.disallowed[flightIndex][alternative] = true
+			}
+			for _, alternative := range pinnedCompositions(solver.crew, flight, solver.pinned[flightIndex], solver.pinnedSeats[flightIndex]) {
+				d.disallowed[flightIndex][alternative] = false
+			}
+		}
+	}
+
+	d.candidates = make([][]int, len(d.seats))
+	d.costs = make([][]int, len(d.seats))
+	d.assigned = make([]int, len(d.seats))
+	d.best = make([]int, len(d.seats))
+	d.twins = make([][]int, len(d.seats))
+
+	for i, st := range d.seats {
+		for j, other := range d.seats {
+			if i != j && other == st {
+				d.twins[i] = append(d.twins[i], j)
+			}
+		}
+
+		d.assigned[i] = NO_CREW
+		d.best[i] = EMPTY_SEAT
+		d.costs[i] = make([]int, len(solver.crew))
+
+		for c := range solver.crew {
+			// Pinned flights only ever rule more out, so they can be checked once up front
+			if solver.canFill(st, c) && d.canTake(c, solver.flightSchedules.Flights[st.flight]) {
+				d.candidates[i] = append(d.candidates[i], c)
+				d.costs[i][c] = solver.seatCost(st, c)
+			}
+		}
+
+		costs := d.costs[i]
+		sort.SliceStable(d.candidates[i], func(a, b int) bool {
+			return costs[d.candidates[i][a]] < costs[d.candidates[i][b]]
+		})
+	}
+
+	d.bestUnfilled = len(d.seats) + 1
+
+	return d
+}
+
+func (d *daySearch) addSeats(flightIndex int, seatRanges map[string]SeatRange, alternative int, pinned map[string]int) {
+	for _, status := range seatStatuses {
+		for n := pinned[status]; n < seatRanges[status].Min; n++ {
+			d.seats = append(d.seats, seat{flight: flightIndex, status: status, alternative: alternative})
+		}
+	}
+}
+
+// One way of picking an alternative for every flight of the day that has them
+type combination struct {
+	choices        map[int]int // Key: flight index; Value: alternative
+	minUnfilled    int         // Seats left unfilled by the cheapest assignment of crew to seats
+	minChanged     int         // Seats that assignment gives to crew who weren't on the flight before a re-plan
+	minSubstituted int         // Seats that assignment fills with substitutes
+	minCost        int         // Cost of that assignment
+}
+
+// Every combination of alternatives, the ones that can fill the most seats first, then the ones changing the
+// fewest seats of a re-plan, then the ones needing the fewest substitutes, then the cheapest and, among those,
+// the ones using alternatives listed earlier first
+func (d *daySearch) combinations() []*combination {
+	combinations := []*combination{{choices: make(map[int]int)}}
+
+	for _, flightIndex := range d.choiceFlights {
+		expanded := []*combination{}
+		for _, c := range combinations {
+			for alternative := 0; alternative < d.alternatives[flightIndex]; alternative++ {
+				if len(expanded) == MAX_COMBINATIONS {
+					d.truncated = true
+					break
+				}
+				if d.disallowed[flightIndex][alternative] {
+					continue
+				}
+
+				choices := make(map[int]int)
+				for k, v := range c.choices {
+					choices[k] = v
+				}
+				choices[flightIndex] = alternative
+				expanded = append(expanded, &combination{choices: choices})
+			}
+		}
+		combinations = expanded
+	}
+
+	d.minUnfilled = len(d.seats)
+	for _, c := range combinations {
+		open, crewBySeat := []int{}, [][]int{}
+		for i, st := range d.seats {
+			if st.alternative == COMMON_SEAT || st.alternative == c.choices[st.flight] {
+				open = append(open, i)
+				crewBySeat = append(crewBySeat, d.candidates[i])
+			}
+		}
+
+		bound, _ := d.assignmentBound(open, crewBySeat)
+		c.minUnfilled, c.minChanged, c.minSubstituted, c.minCost = bound.unfilled, bound.changed, bound.substituted, bound.cost
+	}
+
+	sort.SliceStable(combinations, func(a, b int) bool {
+		if combinations[a].minUnfilled != combinations[b].minUnfilled {
+			return combinations[a].minUnfilled < combinations[b].minUnfilled
+		}
+		if combinations[a].minChanged != combinations[b].minChanged {
+			return combinations[a].minChanged < combinations[b].minChanged
+		}
+		if combinations[a].minSubstituted != combinations[b].minSubstituted {
+			return combinations[a].minSubstituted < combinations[b].minSubstituted
+		}
+		return combinations[a].minCost < combinations[b].minCost
+	})
+	if len(combinations) > 0 {
+		d.minUnfilled = combinations[0].minUnfilled
+		d.minChanged = combinations[0].minChanged
+		d.minSubstituted = combinations[0].minSubstituted
+		d.minCost = combinations[0].minCost
+	}
+
+	return combinations
+}
+
+// Sets the alternatives to search and the seats each flight has with them
+func (d *daySearch) choose(choices map[int]int) {
+	d.choices = choices
+	d.flightSeats = make(map[int][]int)
+	d.remaining = make(map[int]int)
+
+	for i, st := range d.seats {
+		if st.alternative == COMMON_SEAT || st.alternative == choices[st.flight] {
+			d.flightSeats[st.flight] = append(d.flightSeats[st.flight], i)
+			d.remaining[st.flight]++
+		}
+	}
+}
+
+// Hard constraints that don't depend on who else is seated. Crew of another status can fill the seat when the
+// substitutions allow it
+func (solver *scheduleSolver) canFill(st seat, crewIndex int) bool {
+	crew := solver.crew[crewIndex]
+	flight := solver.flightSchedules.Flights[st.flight]
+
+	if _, ok := flightConfig.Substitutions.penalty(st.status, crew.Status); !ok {
+		return false
+	}
+	return crew.canFly(flight) && crew.isQualifiedFor(flight, st.status) && !solver.reserved[st.flight][crewIndex] &&
+		flightConfig.Rest.violation(solver.duties[crewIndex], flight) == ""
+}
+
+// Soft objective: crew with the fewest hours and crew higher in the file first, then whoever has flown least
+// this week and over the last few weeks. Substitutes cost their substitution's penalty on top
+func (solver *scheduleSolver) seatCost(st seat, crewIndex int) int {
+	penalty, _ := flightConfig.Substitutions.penalty(st.status, solver.crew[crewIndex].Status)
+
+	return HOURS_WEIGHT*solver.hoursRank[crewIndex] + PRIORITY_WEIGHT*solver.priority[crewIndex] +
+		REPEAT_FLIGHT_PENALTY*solver.flightsThisWeek[crewIndex] + HISTORY_FLIGHT_PENALTY*solver.recentFlights[crewIndex] +
+		penalty
+}
+
+// Whether the crew member would be substituting in the seat
+func (d *daySearch) isSubstitute(seatIndex int, crewIndex int) bool {
+	return d.solver.crew[crewIndex].Status != d.seats[seatIndex].status
+}
+
+// Whether a re-plan would put the crew member on the seat's flight when they weren't on it before
+func (d *daySearch) isChange(seatIndex int, crewIndex int) bool {
+	previous, ok := d.solver.previous[d.seats[seatIndex].flight]
+	return ok && !previous[crewIndex]
+}
+
+// What seating the crew member adds to a roster, leaving out any pairing penalty
+func (d *daySearch) seatScore(seatIndex int, crewIndex int) seatBound {
+	score := seatBound{cost: d.costs[seatIndex][crewIndex]}
+	if d.isChange(seatIndex, crewIndex) {
+		score.changed = 1
+	}
+	if d.isSubstitute(seatIndex, crewIndex) {
+		score.substituted = 1
+	}
+	return score
+}
+
+// Hard constraints that depend on the rest of the day's partial roster. The other pairing rules can only be
+// checked once the flight's crew is complete
+func (d *daySearch) canSeat(seatIndex int, crewIndex int) bool {
+	return d.canTake(crewIndex, d.solver.flightSchedules.Flights[d.seats[seatIndex].flight]) && d.inTwinOrder(seatIndex, crewIndex) &&
+		!d.neverPaired(seatIndex, crewIndex)
+}
+
+// Crew on the seat's flight so far, pinned crew first
+func (d *daySearch) flightCrew(flightIndex int) []int {
+	crew := append([]int{}, d.solver.pinned[flightIndex]...)
+	for _, i := range d.flightSeats[flightIndex] {
+		if d.assigned[i] >= 0 {
+			crew = append(crew, d.assigned[i])
+		}
+	}
+	return crew
+}
+
+// Whether the pairing rules never put the crew member with anyone already on the seat's flight
+func (d *daySearch) neverPaired(seatIndex int, crewIndex int) bool {
+	pairing := d.solver.pairing
+	if pairing == nil || len(pairing.never[d.solver.crewKeys[crewIndex]]) == 0 {
+		return false
+	}
+
+	for _, other := range d.flightCrew(d.seats[seatIndex].flight) {
+		if pairing.never[d.solver.crewKeys[crewIndex]][d.solver.crewKeys[other]] {
+			return true
+		}
+	}
+	return false
+}
+
+// Penalty for the pairing preferences the seat's flight breaks, and whether it keeps the hard pairing rules.
+// Both only count once every seat on the flight has been decided
+func (d *daySearch) pairingPenalty(seatIndex int) (int, bool) {
+	flightIndex := d.seats[seatIndex].flight
+	if d.solver.pairing == nil || d.remaining[flightIndex] > 0 {
+		return 0, true
+	}
+	return d.solver.pairingCost(flightIndex, d.flightCrew(flightIndex))
+}
+
+// Whether the crew member's duty day policy lets them fly the flight on top of the ones they're on today
+func (d *daySearch) canTake(crewIndex int, flight *Flight) bool {
+	today := d.today[crewIndex]
+	policy := d.solver.dutyDay[crewIndex]
+	return len(today) < policy.MaxFlights && policy.violation(today, flight) == ""
+}
+
+// Twin seats are interchangeable, so only one ordering of them is searched: crew indexes increase with
+// the seat index and empty seats come last
+func (d *daySearch) inTwinOrder(seatIndex int, value int) bool {
+	for _, twin := range d.twins[seatIndex] {
+		other := d.assigned[twin]
+		if other == NO_CREW {
+			continue
+		}
+
+		if twin < seatIndex && !seatValueLess(other, value) {
+			return false
+		}
+		if twin > seatIndex && !seatValueLess(value, other) {
+			return false
+		}
+	}
+	return true
+}
+
+func seatValueLess(a int, b int) bool {
+	if a == EMPTY_SEAT || b == EMPTY_SEAT {
+		return b == EMPTY_SEAT
+	}
+	return a < b
+}
+
+// Puts the crew member in the seat, or leaves it empty for EMPTY_SEAT
+func (d *daySearch) place(seatIndex int, crewIndex int) {
+	d.assigned[seatIndex] = crewIndex
+	d.remaining[d.seats[seatIndex].flight]--
+	if crewIndex != EMPTY_SEAT {
+		d.today[crewIndex] = append(d.today[crewIndex], d.solver.flightSchedules.Flights[d.seats[seatIndex].flight])
+	}
+}
+
+// Undoes place; the search always takes back a crew member's latest seat first
+func (d *daySearch) unplace(seatIndex int, crewIndex int) {
+	d.assigned[seatIndex] = NO_CREW
+	d.remaining[d.seats[seatIndex].flight]++
+	if crewIndex != EMPTY_SEAT {
+		d.today[crewIndex] = d.today[crewIndex][:len(d.today[crewIndex])-1]
+	}
+}
+
+// Branch and bound over the day's seats: fewest unfilled seats first, then, when re-planning, fewest seats
+// given to crew who weren't on the flight before, then fewest substitutes, so crew only fill another status's
+// seat when it would otherwise be left empty, then lowest cost
+func (d *daySearch) search(score seatBound) {
+	d.nodes++
+	if d.isOptimal() {
+		return
+	}
+	if d.nodes > d.budget {
+		d.cutOff = true
+		return
+	}
+
+	next, live, bound := d.scan()
+	if next < 0 {
+		if d.beatsBest(score) {
+			d.bestUnfilled = score.unfilled
+			d.bestChanged = score.changed
+			d.bestSubstituted = score.substituted
+			d.bestCost = score.cost
+			copy(d.best, d.assigned)
+			for flightIndex, alternative := range d.choices {
+				d.bestChoices[flightIndex] = alternative
+			}
+		}
+		return
+	}
+
+	if !d.beatsBest(score.plus(bound)) {
+		return
+	}
+
+	for _, crewIndex := range live {
+		d.place(next, crewIndex)
+		if penalty, ok := d.pairingPenalty(next); ok {
+			d.search(score.plus(d.seatScore(next, crewIndex)).plus(seatBound{cost: penalty}))
+		}
+		d.unplace(next, crewIndex)
+	}
+
+	if d.inTwinOrder(next, EMPTY_SEAT) {
+		d.place(next, EMPTY_SEAT)
+		if penalty, ok := d.pairingPenalty(next); ok {
+			d.search(score.plus(seatBound{unfilled: 1, cost: penalty}))
+		}
+		d.unplace(next, EMPTY_SEAT)
+	}
+}
+
+// Whether a roster scoring the given unfilled seats, changed seats, substitutes and cost would be better than
+// the best so far
+func (d *daySearch) beatsBest(score seatBound) bool {
+	if score.unfilled != d.bestUnfilled {
+		return score.unfilled < d.bestUnfilled
+	}
+	if score.changed != d.bestChanged {
+		return score.changed < d.bestChanged
+	}
+	if score.substituted != d.bestSubstituted {
+		return score.substituted < d.bestSubstituted
+	}
+	return score.cost < d.bestCost
+}
+
+func (d *daySearch) isOptimal() bool {
+	return d.bestUnfilled == d.minUnfilled && d.bestChanged == d.minChanged && d.bestSubstituted == d.minSubstituted &&
+		d.bestCost <= d.minCost
+}
+
+// A roster's score on each level of the objective, or a lower bound on what the seats not yet decided add to it
+type seatBound struct {
+	unfilled    int
+	changed     int
+	substituted int
+	cost        int
+}
+
+func (b seatBound) plus(other seatBound) seatBound {
+	return seatBound{
+		unfilled:    b.unfilled + other.unfilled,
+		changed:     b.changed + other.changed,
+		substituted: b.substituted + other.substituted,
+		cost:        b.cost + other.cost,
+	}
+}
+
+// Picks the open seat with the fewest remaining candidates and returns them, the one the cheapest assignment of
+// crew to the open seats gives it first, along with that assignment's bound on the open seats
+func (d *daySearch) scan() (int, []int, seatBound) {
+	var (
+		next       = -1
+		nextOpen   int
+		open       []int
+		crewBySeat [][]int
+	)
+
+	for i, st := range d.seats {
+		if d.assigned[i] != NO_CREW {
+			continue
+		}
+		if st.alternative != COMMON_SEAT && st.alternative != d.choices[st.flight] {
+			continue
+		}
+
+		live := []int{}
+		for _, crewIndex := range d.candidates[i] {
+			if d.canSeat(i, crewIndex) {
+				live = append(live, crewIndex)
+			}
+		}
+
+		if next < 0 || len(live) < len(crewBySeat[nextOpen]) {
+			next = i
+			nextOpen = len(open)
+		}
+		open = append(open, i)
+		crewBySeat = append(crewBySeat, live)
+	}
+
+	if next < 0 {
+		return next, nil, seatBound{}
+	}
+
+	bound, assignment := d.assignmentBound(open, crewBySeat)
+
+	live := crewBySeat[nextOpen]
+	for i, crewIndex := range live {
+		if crewIndex == assignment[nextOpen] {
+			live = append([]int{crewIndex}, append(append([]int{}, live[:i]...), live[i+1:]...)...)
+			break
+		}
+	}
+
+	return next, live, bound
+}
+
+func NewCrewMember(crew *CrewAvailability) *CrewMember {
+	return &CrewMember{
+		FirstName: crew.FirstName,
+		LastName:  crew.LastName,
+		Rank:      crew.Rank,
+		Status:    crew.Status,
+		Hours:     crew.Hours,
+	}
+}
+
+func seatCrew(flight *Flight, crew *CrewMember) {
+	switch crew.seatStatus() {
+	case "PC":
+		flight.PC = crew
+	case "PI":
+		flight.PIs = append(flight.PIs, crew)
+	case "FE":
+		flight.FEs = append(flight.FEs, crew)
+	case "CE":
+		flight.CEs = append(flight.CEs, crew)
+	}
+}
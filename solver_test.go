@@ -0,0 +1,218 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const testDate = "Jan 05 26"
+
+// Crew of the status available on testDate, in file order, holding the qualifications given for each
+func testCrew(status string, qualifications ...[]string) []*CrewAvailability {
+	crew := []*CrewAvailability{}
+	for i, held := range qualifications {
+		c := &CrewAvailability{
+			FirstName:      fmt.Sprintf("%s%d", status, i),
+			LastName:       "Doe",
+			Rank:           "CW2",
+			Status:         status,
+			Availabilty:    map[string]bool{testDate: true},
+			Codes:          map[string]string{testDate: ""},
+			Qualifications: make(map[string]time.Time),
+		}
+		for _, qualification := range held {
+			c.Qualifications[qualification] = time.Time{}
+		}
+		crew = append(crew, c)
+	}
+	return crew
+}
+
+// Maintenance flights on testDate, one per entry, each requiring the given PC qualifications
+func testMaintenanceFlights(pcRequires ...[]string) *FlightSchedules {
+	flightSchedules := &FlightSchedules{Flights: []*Flight{}}
+	for i, requires := range pcRequires {
+		flight := &Flight{
+			Type:     "MAINTENANCE",
+			Date:     testDate,
+			Time:     fmt.Sprintf("%02d00", 8+3*i),
+			Duration: 2 * time.Hour,
+		}
+		if len(requires) > 0 {
+			flight.Requires = map[string][]string{"PC": requires}
+		}
+		flightSchedules.Flights = append(flightSchedules.Flights, flight)
+	}
+	return flightSchedules
+}
+
+func TestSolveFlightSchedules(t *testing.T) {
+	tests := []struct {
+		name         string
+		pcs          [][]string // Qualifications of each PC, in file order
+		pis          int
+		flights      [][]string // PC qualifications each flight requires
+		wantPCs      []string   // First name of the PC on each flight, blank when it has none
+		wantPIs      []string   // First name of the first PI on each flight
+		wantUnfilled []string
+	}{
+		{
+			// First fit seats PC0 on the first flight, leaving nobody for the second
+			name:    "first fit would strand the test pilot flight",
+			pcs:     [][]string{{"MTP"}, {}},
+			pis:     2,
+			flights: [][]string{{}, {"MTP"}},
+			wantPCs: []string{"PC1", "PC0"},
+			wantPIs: []string{"PI0", "PI1"},
+		},
+		{
+			name:         "fewest unfilled seats when nobody can fly the test pilot flight",
+			pcs:          [][]string{{}, {}},
+			pis:          2,
+			flights:      [][]string{{"MTP"}, {}},
+			wantPCs:      []string{"", "PC0"},
+			wantPIs:      []string{"PI0", "PI1"},
+			wantUnfilled: []string{"PC seat on the 0800 MAINTENANCE flight on " + testDate},
+		},
+		{
+			name:    "higher in the file wins a tie",
+			pcs:     [][]string{{}, {}, {}},
+			pis:     3,
+			flights: [][]string{{}},
+			wantPCs: []string{"PC0"},
+			wantPIs: []string{"PI0"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			crew := testCrew("PC", test.pcs...)
+			crew = append(crew, testCrew("PI", make([][]string, test.pis)...)...)
+			crew = append(crew, testCrew("FE", make([][]string, 2)...)...)
+
+			flightSchedules := testMaintenanceFlights(test.flights...)
+			if err := NewSchedulePayload(crew).solveFlightSchedules(flightSchedules, nil); err != nil {
+				t.Fatal(err)
+			}
+
+			pcs, pis := []string{}, []string{}
+			for _, flight := range flightSchedules.Flights {
+				pc, pi := "", ""
+				if flight.PC != nil {
+					pc = flight.PC.FirstName
+				}
+				if len(flight.PIs) > 0 {
+					pi = flight.PIs[0].FirstName
+				}
+				pcs, pis = append(pcs, pc), append(pis, pi)
+			}
+			if !reflect.DeepEqual(pcs, test.wantPCs) {
+				t.Errorf("PCs = %v, want %v", pcs, test.wantPCs)
+			}
+			if !reflect.DeepEqual(pis, test.wantPIs) {
+				t.Errorf("PIs = %v, want %v", pis, test.wantPIs)
+			}
+
+			unfilled := []string{}
+			for _, seat := range flightSchedules.Unfilled {
+				unfilled = append(unfilled, seat.String())
+			}
+			if len(test.wantUnfilled) == 0 {
+				test.wantUnfilled = []string{}
+			}
+			if !reflect.DeepEqual(unfilled, test.wantUnfilled) {
+				t.Errorf("Unfilled = %v, want %v", unfilled, test.wantUnfilled)
+			}
+			if len(flightSchedules.CutOff) > 0 {
+				t.Errorf("CutOff = %v, want none", flightSchedules.CutOff)
+			}
+		})
+	}
+}
+
+// 48 crew flying 14 days of the default flights is an ordinary roster, so every day's search has to finish
+func TestSolveFlightSchedulesProvesTypicalRostersOptimal(t *testing.T) {
+	var (
+		crew            = []*CrewAvailability{}
+		flightSchedules = &FlightSchedules{Flights: []*Flight{}}
+		start           = time.Date(2026, time.January, 5, 0, 0, 0, 0, time.UTC)
+	)
+
+	for _, status := range []struct {
+		name  string
+		count int
+	}{{"PC", 10}, {"PI", 12}, {"FE", 10}, {"CE", 16}} {
+		crew = append(crew, testCrew(status.name, make([][]string, status.count)...)...)
+	}
+
+	for day := 0; day < 14; day++ {
+		date := start.AddDate(0, 0, day)
+		flights, err := flightConfig.flightsForDay(date, flightConfig.defaultNormalFlights(date), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		flightSchedules.Flights = append(flightSchedules.Flights, flights...)
+
+		for _, c := range crew {
+			c.Availabilty[date.Format(FULL_DATE_FORMAT)] = true
+			c.Codes[date.Format(FULL_DATE_FORMAT)] = ""
+		}
+	}
+
+	if err := NewSchedulePayload(crew).solveFlightSchedules(flightSchedules, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(flightSchedules.CutOff) > 0 {
+		t.Errorf("CutOff = %v, want none", flightSchedules.CutOff)
+	}
+	if len(flightSchedules.Unfilled) > 0 {
+		t.Errorf("Unfilled = %v, want none", flightSchedules.Unfilled)
+	}
+}
+
+// The review page re-solves with every locked crew member pinned, substitutes included
+func TestSolveFlightSchedulesResolvesLockedSubstitutes(t *testing.T) {
+	config := *flightConfig
+	config.Substitutions = Substitutions{"PI": {"PC": 30}}
+	saved := flightConfig
+	flightConfig = &config
+	defer func() { flightConfig = saved }()
+
+	crew := testCrew("PC", nil, []string{"NVG"})
+	crew = append(crew, testCrew("FE", make([][]string, 2)...)...)
+	crew[1].Qualifications["NVG"] = time.Date(2026, time.January, 6, 0, 0, 0, 0, time.UTC)
+
+	flightSchedules := testMaintenanceFlights(nil)
+	flightSchedules.Flights = append(flightSchedules.Flights, &Flight{Type: "MAINTENANCE", Date: "Jan 07 26", Time: "0800", Duration: 2 * time.Hour})
+
+	s := NewSchedulePayload(crew)
+	s.Warnings = []string{"from the input"}
+	if err := s.solveFlightSchedules(flightSchedules, nil); err != nil {
+		t.Fatal(err)
+	}
+	warnings := s.Warnings
+
+	flight := flightSchedules.Flights[0]
+	if len(flight.PIs) != 1 || flight.PIs[0].Seat != "PI" {
+		t.Fatalf("PIs = %v, want a PC substituting", flight.PIs)
+	}
+	substitute := flight.PIs[0]
+
+	s.Pins = []*Pin{{Date: flight.Date, Time: flight.Time, Type: flight.Type, FirstName: substitute.FirstName, LastName: substitute.LastName, Source: "review", Seat: substitute.Seat}}
+	resolved := testMaintenanceFlights(nil)
+	resolved.Flights = append(resolved.Flights, flightSchedules.Flights[1].withoutCrew())
+	if err := s.solveFlightSchedules(resolved, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	pis := resolved.Flights[0].PIs
+	if len(pis) != 1 || pis[0].FirstName != substitute.FirstName || pis[0].Seat != "PI" || !pis[0].Pinned {
+		t.Errorf("PIs = %v, want %s pinned in the PI seat", pis, substitute.FirstName)
+	}
+	if len(warnings) < 2 || !reflect.DeepEqual(s.Warnings, warnings) {
+		t.Errorf("Warnings = %q after re-solving, want %q", s.Warnings, warnings)
+	}
+}
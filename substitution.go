@@ -0,0 +1,66 @@
+package main
+
+import "fmt"
+
+/*
+Crew who can fill a seat of another status, under "substitutions" in the flight config and keyed by the seat's
+status, e.g.
+
+	"substitutions": {
+		"PI": {"PC": 30},
+		"CE": {"FE": 40}
+	}
+
+lets a PC fly in a PI seat and an FE in a CE seat. The number is added to the substitute's cost, so when more
+than one could take the seat the cheapest is seated. Substitutes are only seated when the seat would otherwise
+stay empty, and need the qualifications the flight requires of the seat rather than of their own status.
+Without "substitutions", crew only ever fill seats of their own status.
+*/
+type Substitutions map[string]map[string]int // Key: seat status; Value: penalty by the status of the crew filling it
+
+func (s Substitutions) validate() error {
+	for seatStatus, penalties := range s {
+		if !isCrewStatus(seatStatus) {
+			return fmt.Errorf("substitutions has unknown seat status %q", seatStatus)
+		}
+
+		for crewStatus, penalty := range penalties {
+			if !isCrewStatus(crewStatus) {
+				return fmt.Errorf("substitutions.%s has unknown crew status %q", seatStatus, crewStatus)
+			}
+			if crewStatus == seatStatus {
+				return fmt.Errorf("substitutions.%s lists %s, which fills its own seats already", seatStatus, crewStatus)
+			}
+			if penalty < 0 {
+				return fmt.Errorf("substitutions.%s.%s is negative", seatStatus, crewStatus)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Whether crew of the status can take the seat, and the cost of seating them there on top of their usual one
+func (s Substitutions) penalty(seatStatus string, crewStatus string) (int, bool) {
+	if seatStatus == crewStatus {
+		return 0, true
+	}
+	penalty, ok := s[seatStatus][crewStatus]
+	return penalty, ok
+}
+
+// Status of the seat the crew member is in, which is their own unless they're substituting
+func (c *CrewMember) seatStatus() string {
+	if c.Seat != "" {
+		return c.Seat
+	}
+	return c.Status
+}
+
+// e.g. PI, or "PC as PI" for a PC in a PI seat
+func (c *CrewMember) seatLabel() string {
+	if c.Seat != "" && c.Seat != c.Status {
+		return fmt.Sprintf("%s as %s", c.Status, c.Seat)
+	}
+	return c.Status
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+const substitutionDate = "Apr 06 26"
+
+func TestSolveFlightSchedulesSeatsSubstitutesOnlyInEmptySeats(t *testing.T) {
+	tests := []struct {
+		name          string
+		substitutions Substitutions
+		pis           []string
+		want          []string // Last name and seat label of each crew member on the flight
+		wantUnfilled  int
+	}{
+		{
+			name:          "PI of its own costs more than a substitute",
+			substitutions: Substitutions{"PI": {"PC": 0}},
+			pis:           []string{"Cole"},
+			want:          []string{"Able PC", "Cole PI"},
+		},
+		{
+			name:          "no PI to fly",
+			substitutions: Substitutions{"PI": {"PC": 30}},
+			want:          []string{"Able PC", "Baker PC as PI"},
+		},
+		{
+			name:         "no substitutions",
+			want:         []string{"Able PC"},
+			wantUnfilled: 1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := *flightConfig
+			config.Substitutions = test.substitutions
+			saved := flightConfig
+			flightConfig = &config
+			defer func() { flightConfig = saved }()
+
+			var (
+				crew          = []*CrewAvailability{}
+				recentFlights = make(map[string]int)
+			)
+			addCrew := func(name string, status string) {
+				crew = append(crew, &CrewAvailability{
+					FirstName:   "Lee",
+					LastName:    name,
+					Rank:        "CW2",
+					Status:      status,
+					Availabilty: map[string]bool{substitutionDate: true},
+					Codes:       map[string]string{substitutionDate: ""},
+				})
+			}
+			addCrew("Able", "PC")
+			addCrew("Baker", "PC")
+			for _, name := range test.pis {
+				addCrew(name, "PI")
+				recentFlights[hoursKey("Lee", name)] = 20
+			}
+
+			rule := &CrewRule{Seats: map[string]SeatRange{"PC": {Min: 1, Max: 1}, "PI": {Min: 1, Max: 1}}}
+			flight := &Flight{Type: "MAINTENANCE", Date: substitutionDate, Time: "0900", Duration: 2 * time.Hour, Rule: rule}
+			flightSchedules := &FlightSchedules{Flights: []*Flight{flight}}
+
+			s := NewSchedulePayload(crew)
+			s.RecentFlights = recentFlights
+			if err := s.solveFlightSchedules(flightSchedules, nil); err != nil {
+				t.Fatal(err)
+			}
+
+			got := []string{}
+			for _, c := range flight.crew() {
+				got = append(got, c.LastName+" "+c.seatLabel())
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("crew = %q, want %q", got, test.want)
+			}
+			if len(flightSchedules.Unfilled) != test.wantUnfilled {
+				t.Errorf("Unfilled = %v, want %d", flightSchedules.Unfilled, test.wantUnfilled)
+			}
+		})
+	}
+}
+
+func TestSubstitutionsValidate(t *testing.T) {
+	tests := []struct {
+		substitutions Substitutions
+		want          string
+	}{
+		{substitutions: Substitutions{"PI": {"PC": 30}, "CE": {"FE": 0}}},
+		{substitutions: Substitutions{"XO": {"PC": 30}}, want: `substitutions has unknown seat status "XO"`},
+		{substitutions: Substitutions{"PI": {"XO": 30}}, want: `substitutions.PI has unknown crew status "XO"`},
+		{substitutions: Substitutions{"PI": {"PI": 30}}, want: "substitutions.PI lists PI, which fills its own seats already"},
+		{substitutions: Substitutions{"PI": {"PC": -1}}, want: "substitutions.PI.PC is negative"},
+	}
+
+	for _, test := range tests {
+		got := ""
+		if err := test.substitutions.validate(); err != nil {
+			got = err.Error()
+		}
+		if got != test.want {
+			t.Errorf("validate(%v) = %q, want %q", test.substitutions, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/tealeg/xlsx"
+)
+
+const SUMMARY_SHEET = "Summary"
+
+// Seats crew of a status flew against the days they were available, and how evenly the flights were shared
+type statusUtilization struct {
+	Status        string
+	Crew          int
+	AvailableDays int // Crew-days available to fly
+	Flights       int // Seats flown
+	MinFlights    int // Among crew available at least one day
+	MaxFlights    int
+}
+
+func (u *statusUtilization) utilization() float64 {
+	if u.AvailableDays == 0 {
+		return 0
+	}
+	return float64(u.Flights) / float64(u.AvailableDays)
+}
+
+// Seats a flight type needed against the seats that were filled
+type typeFillRate struct {
+	Type     string
+	Flights  int
+	Needed   int
+	Unfilled int
+}
+
+func (f *typeFillRate) fillRate() float64 {
+	if f.Needed == 0 {
+		return 1
+	}
+	return float64(f.Needed-f.Unfilled) / float64(f.Needed)
+}
+
+// Crew available on a day but not on any of its flights
+type dayUsage struct {
+	Date      string
+	Available map[string]int // Key: status
+	Unused    map[string]int // Key: status
+}
+
+func summarizeStatuses(flightSchedules *FlightSchedules) []*statusUtilization {
+	var (
+		byStatus = make(map[string]*statusUtilization)
+		days     = flightIndexesByDate(flightSchedules)
+		summary  = []*statusUtilization{}
+	)
+
+	for _, status := range seatStatuses {
+		u := &statusUtilization{Status: status, MinFlights: -1}
+		byStatus[status] = u
+		summary = append(summary, u)
+	}
+
+	for _, d := range dutiesByCrew(flightSchedules) {
+		u, ok := byStatus[d.crew.Status]
+		if !ok {
+			continue
+		}
+
+		availableDays := 0
+		for _, day := range days {
+			if d.availability != nil && d.availability.Availabilty[flightSchedules.Flights[day[0]].Date] {
+				availableDays++
+			}
+		}
+
+		u.Crew++
+		u.AvailableDays += availableDays
+		u.Flights += len(d.flights)
+
+		// Crew away the whole time aren't part of the spread
+		if availableDays == 0 && len(d.flights) == 0 {
+			continue
+		}
+		if u.MinFlights < 0 || len(d.flights) < u.MinFlights {
+			u.MinFlights = len(d.flights)
+		}
+		if len(d.flights) > u.MaxFlights {
+			u.MaxFlights = len(d.flights)
+		}
+	}
+
+	for _, u := range summary {
+		if u.MinFlights < 0 {
+			u.MinFlights = 0
+		}
+	}
+
+	return summary
+}
+
+func summarizeFlightTypes(flightSchedules *FlightSchedules) []*typeFillRate {
+	var (
+		byType  = make(map[string]*typeFillRate)
+		summary = []*typeFillRate{}
+	)
+
+	for _, flightType := range flightTypes {
+		f := &typeFillRate{Type: flightType}
+		byType[flightType] = f
+		summary = append(summary, f)
+	}
+
+	for _, flight := range flightSchedules.Flights {
+		f, ok := byType[flight.Type]
+		if !ok {
+			continue
+		}
+
+		f.Flights++
+		for _, seats := range flight.Seats {
+			f.Needed += seats
+		}
+	}
+
+	for _, seat := range flightSchedules.Unfilled {
+		if f, ok := byType[seat.Type]; ok {
+			f.Unfilled++
+		}
+	}
+
+	return summary
+}
+
+func summarizeDays(flightSchedules *FlightSchedules) []*dayUsage {
+	summary := []*dayUsage{}
+
+	for _, day := range flightIndexesByDate(flightSchedules) {
+		date := flightSchedules.Flights[day[0]].Date
+		usage := &dayUsage{Date: date, Available: make(map[string]int), Unused: make(map[string]int)}
+
+		flying := make(map[string]bool)
+		for _, flightIndex := range day {
+			for _, crew := range flightSchedules.Flights[flightIndex].crew() {
+				flying[hoursKey(crew.FirstName, crew.LastName)] = true
+			}
+		}
+
+		for _, crew := range flightSchedules.Crew {
+			if !crew.Availabilty[date] {
+				continue
+			}
+
+			usage.Available[crew.Status]++
+			if !flying[hoursKey(crew.FirstName, crew.LastName)] {
+				usage.Unused[crew.Status]++
+			}
+		}
+
+		summary = append(summary, usage)
+	}
+
+	return summary
+}
+
+func addSummaryRow(sheet *xlsx.Sheet, values ...interface{}) {
+	row := sheet.AddRow()
+	for _, value := range values {
+		cell := row.AddCell()
+		switch v := value.(type) {
+		case int:
+			cell.SetInt(v)
+		case float64:
+			cell.SetFloat(v)
+		default:
+			cell.Value = fmt.Sprint(v)
+		}
+	}
+}
+
+func percent(rate float64) string {
+	return fmt.Sprintf("%.0f%%", rate*100)
+}
+
+// Utilization by status, fill rate by flight type, crew left unused each day, flights per person and, with
+// pairing rules, the pairing preferences that couldn't be kept, so none of it has to be worked out by hand from
+// the flights sheet
+func addSummarySheet(file *xlsx.File, flightSchedules *FlightSchedules) error {
+	sheet, err := file.AddSheet(SUMMARY_SHEET)
+	if err != nil {
+		return err
+	}
+
+	addSummaryRow(sheet, "Utilization by Status")
+	addSheetHeading(sheet, []string{"Status", "Crew", "Available Days", "Flights", "Utilization", "Fewest Flights", "Most Flights", "Spread"})
+	for _, u := range summarizeStatuses(flightSchedules) {
+		addSummaryRow(sheet, u.Status, u.Crew, u.AvailableDays, u.Flights, percent(u.utilization()), u.MinFlights, u.MaxFlights, u.MaxFlights-u.MinFlights)
+	}
+	sheet.AddRow()
+
+	addSummaryRow(sheet, "Fill Rate by Flight Type")
+	addSheetHeading(sheet, []string{"Flight Type", "Flights", "Seats Needed", "Seats Filled", "Fill Rate"})
+	for _, f := range summarizeFlightTypes(flightSchedules) {
+		addSummaryRow(sheet, f.Type, f.Flights, f.Needed, f.Needed-f.Unfilled, percent(f.fillRate()))
+	}
+	sheet.AddRow()
+
+	addSummaryRow(sheet, "Available but Unused Crew by Day")
+	heading := []string{"Date"}
+	for _, status := range seatStatuses {
+		heading = append(heading, fmt.Sprintf("%s Available", status), fmt.Sprintf("%s Unused", status))
+	}
+	addSheetHeading(sheet, heading)
+	for _, usage := range summarizeDays(flightSchedules) {
+		values := []interface{}{usage.Date}
+		for _, status := range seatStatuses {
+			values = append(values, usage.Available[status], usage.Unused[status])
+		}
+		addSummaryRow(sheet, values...)
+	}
+	sheet.AddRow()
+
+	addSummaryRow(sheet, "Flights by Crew Member")
+	heading = []string{"Status", "Rank", "First Name", "Last Name"}
+	heading = append(heading, flightTypes...)
+	heading = append(heading, "Total", "Hours")
+	addSheetHeading(sheet, heading)
+	for _, d := range dutiesByCrew(flightSchedules) {
+		var (
+			byType = make(map[string]int)
+			hours  float64
+		)
+		for _, flight := range d.flights {
+			byType[flight.Type]++
+			hours += flight.Duration.Hours()
+		}
+
+		values := []interface{}{d.crew.Status, d.crew.Rank, d.crew.FirstName, d.crew.LastName}
+		for _, flightType := range flightTypes {
+			values = append(values, byType[flightType])
+		}
+		values = append(values, len(d.flights), hours)
+		addSummaryRow(sheet, values...)
+	}
+
+	if flightSchedules.Pairing == nil {
+		return nil
+	}
+	sheet.AddRow()
+
+	broken := brokenPreferences(flightSchedules)
+	addSummaryRow(sheet, "Broken Pairing Preferences", len(broken))
+	addSheetHeading(sheet, []string{"Date", "Time", "Type", "Status", "Rank", "First Name", "Last Name", "Prefers"})
+	for _, b := range broken {
+		addSummaryRow(sheet, b.Flight.Date, b.Flight.Time, b.Flight.Type, b.Crew.Status, b.Crew.Rank, b.Crew.FirstName, b.Crew.LastName, b.Prefers)
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Two days where Able flies every flight, Baker is only available the first day, Cole is away the whole time and
+// the afternoon flight's PI seat goes unfilled
+func summaryFlightSchedules() *FlightSchedules {
+	crew := []*CrewAvailability{}
+	for i, c := range []struct{ name, status string }{{"Able", "PC"}, {"Baker", "PC"}, {"Cole", "PC"}, {"Dunn", "PI"}} {
+		crew = append(crew, &CrewAvailability{
+			FirstName:   "Sam",
+			LastName:    c.name,
+			Rank:        "CW2",
+			Status:      c.status,
+			Row:         i,
+			Availabilty: map[string]bool{"Mar 02 26": c.name != "Cole", "Mar 03 26": c.name != "Cole" && c.name != "Baker"},
+		})
+	}
+
+	member := func(c *CrewAvailability) *CrewMember {
+		return &CrewMember{FirstName: c.FirstName, LastName: c.LastName, Rank: c.Rank, Status: c.Status}
+	}
+	seats := func() map[string]int { return map[string]int{"PC": 1, "PI": 1} }
+
+	return &FlightSchedules{
+		Crew: crew,
+		Flights: []*Flight{
+			{Type: "MAINTENANCE", Date: "Mar 02 26", Time: "0900", Seats: seats(), PC: member(crew[0]), PIs: []*CrewMember{member(crew[3])}},
+			{Type: "NORMAL", Date: "Mar 02 26", Time: "1300", Seats: seats(), PC: member(crew[0])},
+			{Type: "MAINTENANCE", Date: "Mar 03 26", Time: "0900", Seats: seats(), PC: member(crew[0]), PIs: []*CrewMember{member(crew[3])}},
+		},
+		Unfilled: []*UnfilledSeat{{FlightIndex: 1, Date: "Mar 02 26", Time: "1300", Type: "NORMAL", Status: "PI"}},
+	}
+}
+
+func TestSummarizeStatuses(t *testing.T) {
+	got := summarizeStatuses(summaryFlightSchedules())
+	want := []*statusUtilization{
+		{Status: "PC", Crew: 3, AvailableDays: 3, Flights: 3, MinFlights: 0, MaxFlights: 3}, // Cole isn't part of the spread
+		{Status: "PI", Crew: 1, AvailableDays: 2, Flights: 2, MinFlights: 2, MaxFlights: 2},
+		{Status: "FE"},
+		{Status: "CE"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		for i := range got {
+			t.Errorf("%+v", got[i])
+		}
+		t.Fatalf("summarizeStatuses differs from the above")
+	}
+
+	if utilization := got[1].utilization(); utilization != 1 {
+		t.Errorf("PI utilization = %v, want 1", utilization)
+	}
+	if utilization := got[2].utilization(); utilization != 0 {
+		t.Errorf("FE utilization without available days = %v, want 0", utilization)
+	}
+}
+
+func TestSummarizeFlightTypes(t *testing.T) {
+	got := summarizeFlightTypes(summaryFlightSchedules())
+	want := []*typeFillRate{
+		{Type: "MAINTENANCE", Flights: 2, Needed: 4},
+		{Type: "TRAINING"},
+		{Type: "NORMAL", Flights: 1, Needed: 2, Unfilled: 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("summarizeFlightTypes = %+v, want %+v", got, want)
+	}
+
+	rates := []string{}
+	for _, f := range got {
+		rates = append(rates, percent(f.fillRate()))
+	}
+	if want := []string{"100%", "100%", "50%"}; !reflect.DeepEqual(rates, want) {
+		t.Errorf("fill rates = %q, want %q", rates, want)
+	}
+}
+
+func TestSummarizeDays(t *testing.T) {
+	got := summarizeDays(summaryFlightSchedules())
+	want := []*dayUsage{
+		{Date: "Mar 02 26", Available: map[string]int{"PC": 2, "PI": 1}, Unused: map[string]int{"PC": 1}},
+		{Date: "Mar 03 26", Available: map[string]int{"PC": 1, "PI": 1}, Unused: map[string]int{}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		for i := range got {
+			t.Errorf("%+v", got[i])
+		}
+		t.Fatalf("summarizeDays differs from the above")
+	}
+}